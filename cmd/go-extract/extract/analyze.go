@@ -0,0 +1,348 @@
+package extract
+
+import (
+	"go/ast"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// analyze runs the cross-file analyses over a combined result, with the
+// thresholds opts selects.
+func analyze(result *ExtractResult, opts Options) {
+	attachMethods(result)
+	linkImplementers(result)
+	collectDependencies(result)
+	countFieldUsage(result)
+	flagLargeValueParams(result, opts.largeParamBytes())
+	countIgnoredErrors(result)
+}
+
+// receiverKey identifies a receiver type within one package: the directory
+// and package name of the declaring file, plus the type name.
+type receiverKey struct {
+	dir, pkg, name string
+}
+
+// typeAlias records a package-level alias declaration type name = target
+// whose target is a local type name.
+type typeAlias struct {
+	file, pkg, name, target string
+}
+
+// aliasTargets maps each package-level alias of a local type to the type it
+// names.
+type aliasTargets map[receiverKey]string
+
+// newAliasTargets indexes the aliases declared in result.
+func newAliasTargets(result *ExtractResult) aliasTargets {
+	aliases := make(aliasTargets, len(result.aliases))
+	for _, a := range result.aliases {
+		aliases[receiverKey{filepath.Dir(a.file), a.pkg, a.name}] = a.target
+	}
+	return aliases
+}
+
+// resolve follows aliases from key, through chains such as A = B, B = C, to
+// the defined type they denote. A key that is not an alias is returned as
+// is; the bound on hops stops invalid cyclic aliases.
+func (a aliasTargets) resolve(key receiverKey) receiverKey {
+	for hops := 0; hops < len(a); hops++ {
+		target, ok := a[key]
+		if !ok {
+			break
+		}
+		key.name = target
+	}
+	return key
+}
+
+// receiverOf returns the type a method fn belongs to: its receiver type,
+// with aliases resolved, in fn's package.
+func (a aliasTargets) receiverOf(fn FunctionInfo) receiverKey {
+	return a.resolve(receiverKey{filepath.Dir(fn.File), fn.Package, fn.Receiver})
+}
+
+// attachMethods sets each struct's Methods to the methods declared on it.
+// Methods attach only to a struct in the same package, so a Server in package
+// a never picks up methods of package b's Server. A method declared on an
+// alias (type Foo = Bar) belongs to the aliased type, so it attaches to Bar;
+// the method keeps Foo as its Receiver, and the alias, not being a struct of
+// its own, has no entry. Aliases of types from other packages aren't
+// followed. A struct whose methods all use pointer receivers is flagged as
+// meant to be used via *T; structs with no methods, or any value-receiver
+// method, are not. A struct with a String() string method, on either
+// receiver kind, is flagged as a Stringer.
+func attachMethods(result *ExtractResult) {
+	methodsByReceiver := make(map[receiverKey][]string)
+	valueReceivers := make(map[receiverKey]bool)
+	stringers := make(map[receiverKey]bool)
+	aliases := newAliasTargets(result)
+	for _, fn := range result.Functions {
+		if fn.Receiver == "" {
+			continue
+		}
+		key := aliases.receiverOf(fn)
+		methodsByReceiver[key] = append(methodsByReceiver[key], fn.Name)
+		if !fn.PointerReceiver {
+			valueReceivers[key] = true
+		}
+		if fn.Name == "String" && len(fn.ParamTypes) == 0 && len(fn.Results) == 1 && fn.Results[0] == "string" {
+			stringers[key] = true
+		}
+	}
+
+	for i, s := range result.Structs {
+		key := receiverKey{filepath.Dir(s.File), s.Package, s.Name}
+		methods, ok := methodsByReceiver[key]
+		if !ok {
+			methods = []string{}
+		}
+		result.Structs[i].Methods = methods
+		result.Structs[i].NumMethods = len(methods)
+		result.Structs[i].AllPointerMethods = ok && !valueReceivers[key]
+		result.Structs[i].HasStringMethod = stringers[key]
+	}
+}
+
+// linkImplementers fills in each interface's Implementers with the structs
+// whose attached methods cover every method the interface declares. Matching
+// is by method name only; signatures and embedded interfaces are ignored.
+// Interfaces that declare no methods of their own are left without
+// implementers, since every struct would trivially match.
+func linkImplementers(result *ExtractResult) {
+	for i, iface := range result.Interfaces {
+		implementers := []string{}
+		if len(iface.Methods) > 0 {
+			for _, s := range result.Structs {
+				if hasAllMethods(s.Methods, iface.Methods) {
+					implementers = append(implementers, s.Name)
+				}
+			}
+		}
+		result.Interfaces[i].Implementers = implementers
+	}
+}
+
+// hasAllMethods reports whether have contains every name in want.
+func hasAllMethods(have, want []string) bool {
+	set := make(map[string]bool, len(have))
+	for _, m := range have {
+		set[m] = true
+	}
+	for _, m := range want {
+		if !set[m] {
+			return false
+		}
+	}
+	return true
+}
+
+// FlattenEmbeds fills in PromotedFields for every struct that embeds other
+// structs from the scanned set, following Go's promotion rules: a field at a
+// shallower embedding depth shadows deeper ones, and a name reachable through
+// two embeds at the same depth is ambiguous and not promoted. Embedded field
+// names themselves count as fields. An embedded alias resolves to the struct
+// it names. Embeds of types from other packages (qualified names like
+// io.Reader) or not found in the scanned set stay unresolved.
+func FlattenEmbeds(result *ExtractResult) {
+	aliases := newAliasTargets(result)
+	byKey := make(map[receiverKey]*StructInfo, len(result.Structs))
+	for i := range result.Structs {
+		s := &result.Structs[i]
+		byKey[receiverKey{filepath.Dir(s.File), s.Package, s.Name}] = s
+	}
+	resolve := func(from *StructInfo, embedded string) *StructInfo {
+		name := embeddedTypeName(embedded)
+		if name == "" {
+			return nil
+		}
+		return byKey[aliases.resolve(receiverKey{filepath.Dir(from.File), from.Package, name})]
+	}
+
+	for i := range result.Structs {
+		s := &result.Structs[i]
+		seen := make(map[string]bool)
+		for _, f := range s.Fields {
+			seen[f] = true
+		}
+		visited := map[*StructInfo]bool{s: true}
+		var level []*StructInfo
+		for _, e := range s.Embedded {
+			seen[embeddedTypeName(e)] = true
+			if inner := resolve(s, e); inner != nil && !visited[inner] {
+				visited[inner] = true
+				level = append(level, inner)
+			}
+		}
+
+		promoted := []string{}
+		for len(level) > 0 {
+			counts := make(map[string]int)
+			var order []string
+			var next []*StructInfo
+			for _, inner := range level {
+				names := append([]string{}, inner.Fields...)
+				for _, e := range inner.Embedded {
+					names = append(names, embeddedTypeName(e))
+					if deeper := resolve(inner, e); deeper != nil && !visited[deeper] {
+						visited[deeper] = true
+						next = append(next, deeper)
+					}
+				}
+				for _, name := range names {
+					if name == "" || seen[name] {
+						continue
+					}
+					if counts[name] == 0 {
+						order = append(order, name)
+					}
+					counts[name]++
+				}
+			}
+			for _, name := range order {
+				seen[name] = true
+				if counts[name] == 1 {
+					promoted = append(promoted, name)
+				}
+			}
+			level = next
+		}
+		s.PromotedFields = promoted
+	}
+}
+
+// embeddedTypeName returns the local type name of an embedded type string,
+// stripping a pointer and type arguments (*Base[T] becomes Base). Qualified
+// names from other packages yield "".
+func embeddedTypeName(embedded string) string {
+	name := strings.TrimPrefix(embedded, "*")
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	if strings.Contains(name, ".") {
+		return ""
+	}
+	return name
+}
+
+// collectDependencies sets Dependencies to the sorted, distinct import paths
+// across result's files, and StdDependencies to those in the standard library.
+func collectDependencies(result *ExtractResult) {
+	seen := make(map[string]bool)
+	deps, std := []string{}, []string{}
+	for _, imp := range result.Imports {
+		if seen[imp.Path] {
+			continue
+		}
+		seen[imp.Path] = true
+		deps = append(deps, imp.Path)
+		if imp.Std {
+			std = append(std, imp.Path)
+		}
+	}
+	sort.Strings(deps)
+	sort.Strings(std)
+	result.Dependencies, result.StdDependencies = deps, std
+}
+
+// fieldSelectors counts the selector expressions x.Name in body that may
+// read or write a struct field: selectors on imported package names and
+// callees of calls (x.Start()) are left out.
+func fieldSelectors(body *ast.BlockStmt, scope *fileScope) map[string]int {
+	counts := make(map[string]int)
+	if body == nil {
+		return counts
+	}
+	callees := make(map[*ast.SelectorExpr]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.CallExpr:
+			if sel, ok := node.Fun.(*ast.SelectorExpr); ok {
+				callees[sel] = true
+			}
+		case *ast.SelectorExpr:
+			if x, ok := node.X.(*ast.Ident); ok {
+				if _, imported := scope.imports[x.Name]; imported {
+					return true
+				}
+			}
+			if !callees[node] {
+				counts[node.Sel.Name]++
+			}
+		}
+		return true
+	})
+	return counts
+}
+
+// countFieldUsage sets each struct's FieldUsage to the number of selectors
+// naming each of its fields across the scanned functions. Resolution is by
+// name only, without type checking: x.Name counts for every struct with a
+// field called Name, whatever x is. Unexported fields count only selectors
+// in functions of the struct's own package, where they are visible; exported
+// ones count selectors anywhere. Keyed composite literals (T{Name: v}) are
+// not selectors and don't count, so a field that is only ever initialized
+// reports 0. Function literals extracted with -include-funclits are skipped,
+// since their enclosing function already counts their selectors.
+func countFieldUsage(result *ExtractResult) {
+	type pkgKey struct{ dir, pkg string }
+	perPkg := make(map[pkgKey]map[string]int)
+	all := make(map[string]int)
+	for _, fn := range result.Functions {
+		if fn.IsFuncLit {
+			continue
+		}
+		key := pkgKey{filepath.Dir(fn.File), fn.Package}
+		if perPkg[key] == nil {
+			perPkg[key] = make(map[string]int)
+		}
+		for name, n := range fn.selectors {
+			perPkg[key][name] += n
+			all[name] += n
+		}
+	}
+	for i, s := range result.Structs {
+		usage := make([]int, len(s.Fields))
+		local := perPkg[pkgKey{filepath.Dir(s.File), s.Package}]
+		for j, field := range s.Fields {
+			if isExported(field) {
+				usage[j] = all[field]
+			} else {
+				usage[j] = local[field]
+			}
+		}
+		result.Structs[i].FieldUsage = usage
+	}
+}
+
+// flagLargeValueParams sets each function's LargeValueParams to the names of
+// its parameters whose type is a struct from the scanned set, in the same
+// package, with a SizeBytes estimate over limit. Pointers, slices,
+// variadic parameters and types from other packages are never flagged; an
+// alias of a scanned struct is. Unnamed parameters are listed as "_".
+func flagLargeValueParams(result *ExtractResult, limit int) {
+	aliases := newAliasTargets(result)
+	sizes := make(map[receiverKey]int, len(result.Structs))
+	for _, s := range result.Structs {
+		sizes[receiverKey{filepath.Dir(s.File), s.Package, s.Name}] = s.SizeBytes
+	}
+	for i, fn := range result.Functions {
+		large := []string{}
+		for j, typ := range fn.ParamTypes {
+			if strings.HasPrefix(typ, "*") {
+				continue
+			}
+			name := embeddedTypeName(typ)
+			if name == "" || sizes[aliases.resolve(receiverKey{filepath.Dir(fn.File), fn.Package, name})] <= limit {
+				continue
+			}
+			param := "_"
+			if len(fn.Params) == len(fn.ParamTypes) {
+				param = fn.Params[j]
+			}
+			large = append(large, param)
+		}
+		result.Functions[i].LargeValueParams = large
+	}
+}
@@ -0,0 +1,371 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLinkImplementers(t *testing.T) {
+	result := &ExtractResult{
+		Structs: []StructInfo{
+			{Name: "File", Methods: []string{"Read", "Write", "Close"}},
+			{Name: "Buffer", Methods: []string{"Read"}},
+			{Name: "Config", Methods: []string{}},
+		},
+		Interfaces: []InterfaceInfo{
+			{Name: "ReadCloser", Methods: []string{"Read", "Close"}},
+			{Name: "Reader", Methods: []string{"Read"}},
+			{Name: "Any", Methods: []string{}},
+		},
+	}
+
+	linkImplementers(result)
+
+	if got := result.Interfaces[0].Implementers; len(got) != 1 || got[0] != "File" {
+		t.Errorf("expected ReadCloser implemented by File, got %v", got)
+	}
+	if got := result.Interfaces[1].Implementers; len(got) != 2 {
+		t.Errorf("expected Reader implemented by File and Buffer, got %v", got)
+	}
+	if got := result.Interfaces[2].Implementers; got == nil || len(got) != 0 {
+		t.Errorf("expected empty implementers for method-less interface, got %v", got)
+	}
+}
+
+func TestAttachMethodsAcrossFiles(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "Start", File: "a/server_start.go", Package: "a", Receiver: "Server", PointerReceiver: true},
+			{Name: "Stop", File: "b/server.go", Package: "b", Receiver: "Server"},
+		},
+		Structs: []StructInfo{
+			{Name: "Server", File: "a/server.go", Package: "a"},
+			{Name: "Server", File: "b/server.go", Package: "b"},
+		},
+	}
+
+	attachMethods(result)
+
+	a, b := result.Structs[0], result.Structs[1]
+	if len(a.Methods) != 1 || a.Methods[0] != "Start" {
+		t.Errorf("expected a.Server to have only Start, got %v", a.Methods)
+	}
+	if !a.AllPointerMethods {
+		t.Errorf("expected a.Server to have all pointer methods")
+	}
+	if len(b.Methods) != 1 || b.Methods[0] != "Stop" {
+		t.Errorf("expected b.Server to have only Stop, got %v", b.Methods)
+	}
+	if b.AllPointerMethods {
+		t.Errorf("expected b.Server with a value receiver to report false")
+	}
+}
+
+func TestAttachMethodsStringer(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "String", File: "p/a.go", Package: "p", Receiver: "Point", ParamTypes: []string{}, Results: []string{"string"}},
+			{Name: "String", File: "p/a.go", Package: "p", Receiver: "Name", ParamTypes: []string{"int"}, Results: []string{"string"}},
+			{Name: "String", File: "p/a.go", Package: "p", Receiver: "Buf", PointerReceiver: true, ParamTypes: []string{}, Results: []string{"string", "error"}},
+		},
+		Structs: []StructInfo{
+			{Name: "Point", File: "p/a.go", Package: "p"},
+			{Name: "Name", File: "p/a.go", Package: "p"},
+			{Name: "Buf", File: "p/a.go", Package: "p"},
+		},
+	}
+
+	attachMethods(result)
+
+	for i, want := range []bool{true, false, false} {
+		if s := result.Structs[i]; s.HasStringMethod != want {
+			t.Errorf("%s: expected has_string_method %v, got %v", s.Name, want, s.HasStringMethod)
+		}
+	}
+}
+
+func TestFlattenEmbeds(t *testing.T) {
+	result := &ExtractResult{
+		Structs: []StructInfo{
+			{Name: "Outer", File: "p/a.go", Package: "p", Fields: []string{"ID"}, Embedded: []string{"*Left", "Right", "io.Reader"}},
+			{Name: "Left", File: "p/a.go", Package: "p", Fields: []string{"ID", "Name", "Shared"}, Embedded: []string{"Base"}},
+			{Name: "Right", File: "p/b.go", Package: "p", Fields: []string{"Shared", "Size"}},
+			{Name: "Base", File: "p/b.go", Package: "p", Fields: []string{"Created", "Size"}, Embedded: []string{"*Outer"}},
+			{Name: "Left", File: "q/a.go", Package: "q", Fields: []string{"Other"}},
+		},
+	}
+
+	FlattenEmbeds(result)
+
+	// ID is shadowed by Outer's own field, Shared is ambiguous at depth 1,
+	// Size at depth 1 (Right) shadows Base's Size at depth 2, and the cycle
+	// back to Outer yields only the embedded field name itself.
+	expected := []string{"Name", "Base", "Size", "Created", "Outer"}
+	got := result.Structs[0].PromotedFields
+	if len(got) != len(expected) {
+		t.Fatalf("expected promoted fields %v, got %v", expected, got)
+	}
+	for i, want := range expected {
+		if got[i] != want {
+			t.Errorf("promoted %d: expected %s, got %s", i, want, got[i])
+		}
+	}
+	if got := result.Structs[2].PromotedFields; got == nil || len(got) != 0 {
+		t.Errorf("expected empty promoted fields for Right, got %v", got)
+	}
+}
+
+func TestEmbeddedTypeName(t *testing.T) {
+	tests := map[string]string{
+		"Base":        "Base",
+		"*Base":       "Base",
+		"List[T]":     "List",
+		"io.Reader":   "",
+		"*sync.Mutex": "",
+	}
+	for in, want := range tests {
+		if got := embeddedTypeName(in); got != want {
+			t.Errorf("%s: expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestAttachMethodsMultiParamGenericValueReceiver(t *testing.T) {
+	dir := t.TempDir()
+	types := filepath.Join(dir, "map.go")
+	methods := filepath.Join(dir, "map_len.go")
+	os.WriteFile(types, []byte("package main\n\ntype Map[K comparable, V any] struct {\n\titems map[K]V\n}\n"), 0644)
+	os.WriteFile(methods, []byte("package main\n\nfunc (m Map[K, V]) Len() int { return len(m.items) }\n"), 0644)
+
+	result := ExtractFiles([]string{types, methods}, Options{})
+
+	length := result.Functions[0]
+	if length.Receiver != "Map" || length.ReceiverFull != "Map[K, V]" || length.PointerReceiver {
+		t.Errorf("expected value receiver Map[K, V], got %q/%q pointer=%v", length.Receiver, length.ReceiverFull, length.PointerReceiver)
+	}
+	m := result.Structs[0]
+	if len(m.Methods) != 1 || m.Methods[0] != "Len" {
+		t.Errorf("expected Len attached to Map, got %v", m.Methods)
+	}
+	if m.AllPointerMethods {
+		t.Errorf("expected Map with a value receiver not to report all pointer methods")
+	}
+}
+
+func TestCollectDependencies(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	os.WriteFile(a, []byte("package main\n\nimport (\n\t\"fmt\"\n\tyaml \"gopkg.in/yaml.v3\"\n\t\"net/http\"\n)\n"), 0644)
+	os.WriteFile(b, []byte("package main\n\nimport (\n\t\"fmt\"\n\t_ \"github.com/lib/pq\"\n)\n"), 0644)
+
+	result := ExtractFiles([]string{a, b}, Options{})
+
+	if len(result.Imports) != 5 {
+		t.Fatalf("expected 5 imports, got %+v", result.Imports)
+	}
+	yaml := result.Imports[1]
+	if yaml.Name != "yaml" || yaml.Path != "gopkg.in/yaml.v3" || yaml.Std || yaml.Line != 5 || yaml.Package != "main" {
+		t.Errorf("unexpected yaml import %+v", yaml)
+	}
+	expected := "fmt,github.com/lib/pq,gopkg.in/yaml.v3,net/http"
+	if got := strings.Join(result.Dependencies, ","); got != expected {
+		t.Errorf("expected dependencies %s, got %s", expected, got)
+	}
+	if got := strings.Join(result.StdDependencies, ","); got != "fmt,net/http" {
+		t.Errorf("expected std dependencies fmt,net/http, got %s", got)
+	}
+}
+
+func TestCountFieldUsage(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"server/server.go": `package server
+
+import "fmt"
+
+type Server struct {
+	Name    string
+	addr    string
+	retries int
+	Unused  bool
+}
+
+func (s *Server) Start() {
+	fmt.Println(s.Name, s.addr)
+	s.addr = "localhost"
+	s.Start()
+	_ = Server{retries: 3}
+}
+`,
+		"client/client.go": `package client
+
+func Use(s *server.Server, c *Client) {
+	_ = s.Name
+	_ = c.addr
+}
+`,
+	})
+
+	result := ExtractFiles([]string{filepath.Join(dir, "server/server.go"), filepath.Join(dir, "client/client.go")}, Options{})
+
+	// Name: twice; addr: twice in its own package, not via client's c.addr;
+	// retries: only in a keyed literal; Unused: never.
+	expected := []int{2, 2, 0, 0}
+	got := result.Structs[0].FieldUsage
+	if len(got) != len(expected) {
+		t.Fatalf("expected usage %v, got %v", expected, got)
+	}
+	for i, want := range expected {
+		if got[i] != want {
+			t.Errorf("%s: expected usage %d, got %d", result.Structs[0].Fields[i], want, got[i])
+		}
+	}
+}
+
+func TestFlagLargeValueParams(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"big/big.go": `package big
+
+type Big struct {
+	Data [32]int64
+}
+
+type Small struct {
+	ID int
+}
+
+func Process(b Big, p *Big, s Small, rest ...Big) {}
+
+func Copy(Big, Big) {}
+
+func Many(bs []Big) {}
+`,
+		"other/other.go": `package other
+
+func Use(b big.Big) {}
+`,
+	})
+
+	result := ExtractFiles([]string{filepath.Join(dir, "big/big.go"), filepath.Join(dir, "other/other.go")}, Options{})
+
+	expected := map[string][]string{
+		"Process": {"b"},
+		"Copy":    {"_", "_"},
+		"Many":    {},
+		"Use":     {},
+	}
+	for _, fn := range result.Functions {
+		want := expected[fn.Name]
+		if strings.Join(fn.LargeValueParams, ",") != strings.Join(want, ",") || fn.LargeValueParams == nil {
+			t.Errorf("expected %s large value params %v, got %#v", fn.Name, want, fn.LargeValueParams)
+		}
+	}
+
+	analyze(result, Options{LargeParamBytes: 512})
+	if got := result.Functions[0].LargeValueParams; len(got) != 0 {
+		t.Errorf("expected no large value params above 512 bytes, got %v", got)
+	}
+}
+
+func TestAttachMethodsThroughAlias(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"store/store.go": `package store
+
+type Bar struct{ n int }
+
+func (b *Bar) Get() int { return b.n }
+`,
+		"store/alias.go": `package store
+
+type Foo = Bar
+
+type Baz = Foo
+
+func (f *Foo) Set(n int) { f.n = n }
+
+func (b Baz) String() string { return "baz" }
+`,
+		"other/other.go": `package other
+
+type Foo struct{}
+
+func (f Foo) Other() {}
+`,
+	})
+
+	result := ExtractFiles([]string{
+		filepath.Join(dir, "store/store.go"),
+		filepath.Join(dir, "store/alias.go"),
+		filepath.Join(dir, "other/other.go"),
+	}, Options{})
+
+	if len(result.Structs) != 2 {
+		t.Fatalf("expected Bar and other's Foo, got %d structs", len(result.Structs))
+	}
+	bar := result.Structs[0]
+	if strings.Join(bar.Methods, ",") != "Get,Set,String" {
+		t.Errorf("expected Bar to have Get,Set,String, got %v", bar.Methods)
+	}
+	if bar.AllPointerMethods || !bar.HasStringMethod {
+		t.Errorf("expected Bar to have a value-receiver String method, got %+v", bar)
+	}
+	if other := result.Structs[1]; strings.Join(other.Methods, ",") != "Other" {
+		t.Errorf("expected other's Foo to have only Other, got %v", other.Methods)
+	}
+	if set := result.Functions[1]; set.Receiver != "Foo" {
+		t.Errorf("expected Set to keep its Foo receiver, got %s", set.Receiver)
+	}
+}
+
+func TestAliasMethodsAcrossConsumers(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "store.go")
+	os.WriteFile(src, []byte(`package store
+
+type Base struct{ Created int }
+
+// Store stores.
+type Store struct {
+	Ref
+}
+
+type Ref = Base
+
+type Alias = Store
+
+// Close closes s.
+func (s *Alias) Close() error { return nil }
+`), 0644)
+
+	result := ExtractFiles([]string{src}, Options{})
+
+	infos, err := CheckSatisfies(result, "io.Closer")
+	if err != nil {
+		t.Fatalf("checkSatisfies failed: %v", err)
+	}
+	if store := infos[1]; store.Struct != "Store" || !store.Satisfies || !store.PointerOnly {
+		t.Errorf("expected *Store to satisfy io.Closer through its alias, got %+v", store)
+	}
+
+	var md strings.Builder
+	if err := WriteMarkdown(&md, result); err != nil {
+		t.Fatalf("writeMarkdown failed: %v", err)
+	}
+	if !strings.Contains(md.String(), "Close closes s.") {
+		t.Errorf("expected Close documented under Store, got:\n%s", md.String())
+	}
+
+	if store := lspOutline(result)[src][1]; store.Name != "Store" || len(store.Children) != 1 || store.Children[0].Name != "Close" {
+		t.Errorf("expected Close as a child of Store, got %+v", store)
+	}
+
+	FlattenEmbeds(result)
+	if got := result.Structs[1].PromotedFields; strings.Join(got, ",") != "Created" {
+		t.Errorf("expected Created promoted through the Ref alias, got %v", got)
+	}
+}
@@ -0,0 +1,135 @@
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// zipMagic starts every zip archive.
+var zipMagic = []byte("PK\x03\x04")
+
+// ExtractArchive extracts every .go entry of a zip or tar archive, the latter
+// optionally gzip-compressed, without unpacking it to disk. Entry paths
+// become the File field; non-Go entries are skipped and entries that fail to
+// parse are reported to opts.FileError.
+func ExtractArchive(path string, opts Options) (*ExtractResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("opening archive: %w", err)
+	}
+	magic := make([]byte, len(zipMagic))
+	if _, err := f.ReadAt(magic, 0); err == nil && bytes.Equal(magic, zipMagic) {
+		return extractZip(f, info.Size(), opts)
+	}
+	return extractTar(f, opts)
+}
+
+// FetchArchive downloads the zip or tar(.gz) archive at url into memory,
+// giving up after timeout, and extracts it like ExtractArchive.
+func FetchArchive(url string, timeout time.Duration, opts Options) (*ExtractResult, error) {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", url, err)
+	}
+	if bytes.HasPrefix(data, zipMagic) {
+		return extractZip(bytes.NewReader(data), int64(len(data)), opts)
+	}
+	return extractTar(bytes.NewReader(data), opts)
+}
+
+// extractZip extracts the .go entries of the zip archive in r.
+func extractZip(r io.ReaderAt, size int64, opts Options) (*ExtractResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("reading zip: %w", err)
+	}
+	combined := newResult()
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !strings.HasSuffix(f.Name, ".go") {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		src, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+		result, err := extractBytes(f.Name, src, opts)
+		if err != nil {
+			opts.fileError(f.Name, err)
+			continue
+		}
+		combined.merge(result)
+	}
+	analyze(combined, opts)
+	return combined, nil
+}
+
+// extractTar extracts the .go entries of the tar stream r, transparently
+// decompressing it when it starts with the gzip magic bytes.
+func extractTar(r io.Reader, opts Options) (*ExtractResult, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	combined := newResult()
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !strings.HasSuffix(hdr.Name, ".go") {
+			continue
+		}
+		src, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", hdr.Name, err)
+		}
+		result, err := extractBytes(hdr.Name, src, opts)
+		if err != nil {
+			opts.fileError(hdr.Name, err)
+			continue
+		}
+		combined.merge(result)
+	}
+	analyze(combined, opts)
+	return combined, nil
+}
@@ -0,0 +1,127 @@
+package extract
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTarGz writes a gzip-compressed tar archive containing files.
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("creating archive failed: %v", err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+}
+
+func TestExtractArchive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snapshot.tar.gz")
+	writeTarGz(t, path, map[string]string{
+		"repo/server/server.go": "package server\n\ntype Server struct{}\n",
+		"repo/server/start.go":  "package server\n\nfunc (s *Server) Start() {}\n",
+		"repo/README.md":        "# not go\n",
+	})
+
+	result, err := ExtractArchive(path, Options{})
+	if err != nil {
+		t.Fatalf("extractArchive failed: %v", err)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].File != "repo/server/start.go" {
+		t.Fatalf("expected Start from repo/server/start.go, got %v", result.Functions)
+	}
+	if len(result.Structs) != 1 || len(result.Structs[0].Methods) != 1 {
+		t.Errorf("expected Start attached to Server across entries, got %v", result.Structs)
+	}
+}
+
+func TestExtractArchiveNotFound(t *testing.T) {
+	if _, err := ExtractArchive("/nonexistent/archive.tar.gz", Options{}); err == nil {
+		t.Fatalf("expected error for missing archive")
+	}
+}
+
+// zipBytes returns a zip archive containing files in the given order.
+func zipBytes(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		w, err := zw.Create(files[i])
+		if err != nil {
+			t.Fatalf("creating zip entry failed: %v", err)
+		}
+		w.Write([]byte(files[i+1]))
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestFetchArchiveZip(t *testing.T) {
+	data := zipBytes(t,
+		"repo-main/", "",
+		"repo-main/server/server.go", "package server\n\ntype Server struct{}\n",
+		"repo-main/server/start.go", "package server\n\nfunc (s *Server) Start() {}\n",
+		"repo-main/README.md", "# not go\n",
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	result, err := FetchArchive(srv.URL+"/archive.zip", time.Minute, Options{})
+	if err != nil {
+		t.Fatalf("fetchArchive failed: %v", err)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].File != "repo-main/server/start.go" {
+		t.Errorf("expected Start from repo-main/server/start.go, got %+v", result.Functions)
+	}
+	if len(result.Structs) != 1 || len(result.Structs[0].Methods) != 1 {
+		t.Errorf("expected Server with Start attached, got %+v", result.Structs)
+	}
+}
+
+func TestFetchArchiveHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := FetchArchive(srv.URL+"/missing.zip", time.Minute, Options{})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a 404 error, got %v", err)
+	}
+}
+
+func TestFetchArchiveTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	_, err := FetchArchive(srv.URL+"/slow.zip", 50*time.Millisecond, Options{})
+	if err == nil || !strings.Contains(err.Error(), "downloading") {
+		t.Errorf("expected a download timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package extract
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// AssertionInfo describes a compile-time interface assertion such as
+// var _ io.Reader = (*File)(nil).
+type AssertionInfo struct {
+	File      string `json:"file"`
+	Package   string `json:"package"`
+	Line      int    `json:"line"`
+	Type      string `json:"type"`
+	Interface string `json:"interface"`
+}
+
+// extractAssertions returns the interface assertions of a top-level var
+// declaration: specs that declare only _ with an explicit type and assign
+// each a value whose type can be read off the syntax: (*T)(nil), T(nil),
+// T{}, &T{}, or new(T). Without type checking the declared type is taken to
+// be an interface; var _ int = len(x) and the like don't match the value
+// forms and are ignored.
+func extractAssertions(fset *token.FileSet, decl *ast.GenDecl, filename string) []AssertionInfo {
+	var assertions []AssertionInfo
+	for _, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok || vs.Type == nil || len(vs.Values) != len(vs.Names) {
+			continue
+		}
+		for i, name := range vs.Names {
+			if name.Name != "_" {
+				continue
+			}
+			typ := assertedType(vs.Values[i])
+			if typ == "" {
+				continue
+			}
+			assertions = append(assertions, AssertionInfo{
+				File:      filename,
+				Line:      fset.Position(name.Pos()).Line,
+				Type:      typ,
+				Interface: typeString(vs.Type),
+			})
+		}
+	}
+	return assertions
+}
+
+// assertedType returns the type of an assertion's value expression, or "" if
+// it is not one of the forms extractAssertions recognizes.
+func assertedType(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		if len(e.Args) == 1 {
+			if id, ok := e.Args[0].(*ast.Ident); ok && id.Name == "nil" {
+				// (*T)(nil) or T(nil).
+				return typeString(ast.Unparen(e.Fun))
+			}
+			if id, ok := e.Fun.(*ast.Ident); ok && id.Name == "new" {
+				return "*" + typeString(e.Args[0])
+			}
+		}
+	case *ast.CompositeLit:
+		if e.Type != nil {
+			return typeString(e.Type)
+		}
+	case *ast.UnaryExpr:
+		if lit, ok := e.X.(*ast.CompositeLit); ok && e.Op == token.AND && lit.Type != nil {
+			return "*" + typeString(lit.Type)
+		}
+	}
+	return ""
+}
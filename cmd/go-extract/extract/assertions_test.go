@@ -0,0 +1,74 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractAssertions(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "reader.go")
+	os.WriteFile(src, []byte(`package reader
+
+import (
+	"fmt"
+	"io"
+)
+
+type File struct{}
+type Name string
+
+var _ io.Reader = (*File)(nil)
+var _ fmt.Stringer = Name("")
+
+var (
+	_ io.Closer = File{}
+	_ io.Writer = &File{}
+	_ io.Seeker = new(File)
+	_ fmt.Stringer = Name(nil)
+)
+
+var _ int = len("x")
+var _ = (*File)(nil)
+var x io.Reader = (*File)(nil)
+
+func f() {
+	var _ io.Reader = (*File)(nil)
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []AssertionInfo{
+		{File: src, Package: "reader", Line: 11, Type: "*File", Interface: "io.Reader"},
+		{File: src, Package: "reader", Line: 15, Type: "File", Interface: "io.Closer"},
+		{File: src, Package: "reader", Line: 16, Type: "*File", Interface: "io.Writer"},
+		{File: src, Package: "reader", Line: 17, Type: "*File", Interface: "io.Seeker"},
+		{File: src, Package: "reader", Line: 18, Type: "Name", Interface: "fmt.Stringer"},
+	}
+	if len(result.Assertions) != len(want) {
+		t.Fatalf("expected %d assertions, got %d: %+v", len(want), len(result.Assertions), result.Assertions)
+	}
+	for i, w := range want {
+		if result.Assertions[i] != w {
+			t.Errorf("expected assertion %d to be %+v, got %+v", i, w, result.Assertions[i])
+		}
+	}
+}
+
+func TestExtractAssertionsEmpty(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.go")
+	os.WriteFile(src, []byte("package a\n\nvar x = 1\n"), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Assertions == nil || len(result.Assertions) != 0 {
+		t.Errorf("expected empty non-nil assertions, got %#v", result.Assertions)
+	}
+}
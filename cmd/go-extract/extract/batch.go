@@ -0,0 +1,223 @@
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// walkGoFiles calls visit with each .go file under root in lexical order,
+// skipping the directories ExtractDir skips. With opts.FollowSymlinks, symlinked
+// directories are walked under the link's path, and every directory walked
+// is recorded by its dirID (device and inode on Unix), so a link back to an
+// ancestor, or a second path to a directory, is not walked again.
+func walkGoFiles(root string, opts Options, visit func(path string)) error {
+	visited := make(map[any]bool)
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		if opts.FollowSymlinks {
+			info, err := os.Stat(dir)
+			if err != nil {
+				return err
+			}
+			id, err := dirID(dir, info)
+			if err != nil {
+				return err
+			}
+			if visited[id] {
+				return nil
+			}
+			visited[id] = true
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			path := filepath.Join(dir, name)
+			isDir := entry.IsDir()
+			if entry.Type()&fs.ModeSymlink != 0 && opts.FollowSymlinks {
+				// A dangling link is left to fail as a file, if it is one.
+				if info, err := os.Stat(path); err == nil && info.IsDir() {
+					isDir = true
+				}
+			}
+			if isDir {
+				if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+					continue
+				}
+				if err := walk(path); err != nil {
+					return err
+				}
+				continue
+			}
+			if !strings.HasSuffix(name, ".go") || (opts.SkipTests && strings.HasSuffix(name, "_test.go")) {
+				continue
+			}
+			visit(path)
+		}
+		return nil
+	}
+	return walk(root)
+}
+
+// ExtractDir extracts every .go file under root and returns the combined
+// result with the cross-file analyses applied, as the CLI would for the same
+// files listed in walk order. Like the go tool, it skips vendor and testdata
+// directories and those whose names start with . or _. Files that fail to
+// parse are reported to opts.FileError and skipped; only a missing root or a
+// walk failure is returned as an error.
+func ExtractDir(root string, opts Options) (*ExtractResult, error) {
+	if err := checkDir(root); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	if err := walkGoFiles(root, opts, func(path string) { paths = append(paths, path) }); err != nil {
+		return nil, fmt.Errorf("walking %s: %w", root, err)
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Results are stored by index and merged in walk order afterwards, so
+	// the output does not depend on scheduling.
+	results := make([]*ExtractResult, len(paths))
+	errs := make([]error, len(paths))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i], errs[i] = extractFile(paths[i], opts)
+				opts.Progress.Increment()
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	combined := newResult()
+	for i, path := range paths {
+		if errs[i] != nil {
+			opts.fileError(path, errs[i])
+			continue
+		}
+		combined.merge(results[i])
+	}
+	analyze(combined, opts)
+	if opts.ExportedOnly {
+		filterExported(combined)
+	}
+	if opts.Process != nil {
+		combined = opts.Process(combined)
+	}
+	return combined, nil
+}
+
+// StreamDir extracts every .go file under root, like ExtractDir, but writes
+// each file's result to w as one line of JSON as soon as the file is parsed,
+// so output starts before the walk finishes and memory stays bounded by the
+// files in flight rather than the whole tree. Lines come in completion
+// order; within a line, entities keep their order in the file. Each file is
+// analyzed on its own, as a one-file input would be: cross-file links are out
+// of scope, so methods, implementers, field usage, large value parameters and
+// ignored errors only account for declarations in the same file. If w has a
+// Flush method it is called after each line.
+func StreamDir(root string, opts Options, w io.Writer) error {
+	if err := checkDir(root); err != nil {
+		return err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	type extracted struct {
+		path   string
+		result *ExtractResult
+		err    error
+	}
+	paths := make(chan string)
+	results := make(chan extracted)
+	var walkErr error
+	go func() {
+		walkErr = walkGoFiles(root, opts, func(path string) { paths <- path })
+		close(paths)
+	}()
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				result, err := extractFile(path, opts)
+				opts.Progress.Increment()
+				results <- extracted{path, result, err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Results are drained even after a write error so the workers and the
+	// walk can finish.
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(interface{ Flush() error })
+	var writeErr error
+	for r := range results {
+		if writeErr != nil {
+			continue
+		}
+		if r.err != nil {
+			opts.fileError(r.path, r.err)
+			continue
+		}
+		result := r.result
+		analyze(result, opts)
+		if opts.ExportedOnly {
+			filterExported(result)
+		}
+		if opts.Process != nil {
+			result = opts.Process(result)
+		}
+		if writeErr = enc.Encode(result); writeErr == nil && flusher != nil {
+			writeErr = flusher.Flush()
+		}
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if walkErr != nil {
+		return fmt.Errorf("walking %s: %w", root, walkErr)
+	}
+	return nil
+}
+
+// checkDir returns an error unless root is an existing directory.
+func checkDir(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", root)
+	}
+	return nil
+}
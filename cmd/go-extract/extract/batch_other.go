@@ -0,0 +1,15 @@
+//go:build !unix
+
+package extract
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// dirID returns the directory at path with every symlink resolved, to tell
+// directories reached through different paths apart where inode numbers
+// aren't available.
+func dirID(path string, info os.FileInfo) (any, error) {
+	return filepath.EvalSymlinks(path)
+}
@@ -0,0 +1,262 @@
+package extract
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordFailures returns an Options.FileError that appends each failed path
+// to failed.
+func recordFailures(failed *[]string) func(string, error) {
+	return func(path string, _ error) { *failed = append(*failed, path) }
+}
+
+// writeTree writes files, keyed by slash-separated path, under dir.
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating %s failed: %v", name, err)
+		}
+		os.WriteFile(path, []byte(content), 0644)
+	}
+}
+
+func TestExtractDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"server.go":               "package app\n\ntype Server struct{}\n\nfunc (s *Server) Start() {}\n\nfunc helper() {}\n",
+		"server_test.go":          "package app\n\nfunc TestStart() {}\n",
+		"store/store.go":          "package store\n\ntype Store interface{ Get() }\n\ntype cache struct{}\n\nfunc (c *cache) Get() {}\n\nconst Size = 1\n",
+		"vendor/dep/dep.go":       "package dep\n\nfunc Vendored() {}\n",
+		"testdata/fixture.go":     "package fixture\n\nfunc Fixture() {}\n",
+		".git/hooks/hook.go":      "package hooks\n\nfunc Hook() {}\n",
+		"store/README.md":         "# not go\n",
+		"store/_scratch/draft.go": "package draft\n\nfunc Draft() {}\n",
+	})
+
+	names := func(result *ExtractResult) string {
+		var names []string
+		for _, fn := range result.Functions {
+			names = append(names, fn.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	all, err := ExtractDir(dir, Options{Workers: 2})
+	if err != nil {
+		t.Fatalf("ExtractDir failed: %v", err)
+	}
+	if got := names(all); got != "Start,helper,TestStart,Get" {
+		t.Errorf("expected Start,helper,TestStart,Get, got %s", got)
+	}
+	if len(all.Structs) != 2 || len(all.Structs[0].Methods) != 1 {
+		t.Errorf("expected Server with Start attached and cache, got %+v", all.Structs)
+	}
+
+	exported, err := ExtractDir(dir, Options{SkipTests: true, ExportedOnly: true})
+	if err != nil {
+		t.Fatalf("ExtractDir failed: %v", err)
+	}
+	if got := names(exported); got != "Start" {
+		t.Errorf("expected only Start, got %s", got)
+	}
+	if len(exported.Structs) != 1 || exported.Structs[0].Name != "Server" {
+		t.Errorf("expected only Server, got %+v", exported.Structs)
+	}
+	if len(exported.Interfaces) != 1 || len(exported.Values) != 1 {
+		t.Errorf("expected Store and Size, got %+v and %+v", exported.Interfaces, exported.Values)
+	}
+}
+
+func TestExtractDirNotADirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	os.WriteFile(file, []byte("package a\n"), 0644)
+
+	if _, err := ExtractDir(file, Options{}); err == nil {
+		t.Errorf("expected an error for a file root")
+	}
+	if _, err := ExtractDir(filepath.Join(dir, "missing"), Options{}); err == nil {
+		t.Errorf("expected an error for a missing root")
+	}
+}
+
+func TestExtractDirFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"app/main.go":      "package main\n\nfunc main() {}\n",
+		"shared/shared.go": "package shared\n\nfunc Shared() {}\n",
+	})
+	// app/lib links to the shared tree, and shared/loop back to the root.
+	if err := os.Symlink(filepath.Join(dir, "shared"), filepath.Join(dir, "app", "lib")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if err := os.Symlink(dir, filepath.Join(dir, "shared", "loop")); err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, "app")
+
+	plain, err := ExtractDir(root, Options{})
+	if err != nil {
+		t.Fatalf("ExtractDir failed: %v", err)
+	}
+	if len(plain.Functions) != 1 {
+		t.Errorf("expected only main without FollowSymlinks, got %d functions", len(plain.Functions))
+	}
+
+	followed, err := ExtractDir(root, Options{FollowSymlinks: true})
+	if err != nil {
+		t.Fatalf("ExtractDir failed: %v", err)
+	}
+	var files []string
+	for _, fn := range followed.Functions {
+		files = append(files, fn.File)
+	}
+	// The loop leads back to dir, whose app and shared children have
+	// already been walked.
+	expected := []string{
+		filepath.Join(root, "lib", "shared.go"),
+		filepath.Join(root, "main.go"),
+	}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected files %v, got %v", expected, files)
+	}
+}
+
+// flushCounter records the lines written to it and how often it is flushed.
+type flushCounter struct {
+	strings.Builder
+	flushes int
+}
+
+func (f *flushCounter) Flush() error {
+	f.flushes++
+	return nil
+}
+
+func TestExtractDirProcess(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a.go": "package app\n\nfunc A() {}\n\nfunc b() {}\n",
+		"c.go": "package app\n\nfunc C() {}\n",
+	})
+
+	var calls int
+	process := func(result *ExtractResult) *ExtractResult {
+		calls++
+		FilterBySymbol(result, "A")
+		return result
+	}
+
+	result, err := ExtractDir(dir, Options{Process: process})
+	if err != nil {
+		t.Fatalf("ExtractDir failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected Process to run once on the combined result, got %d calls", calls)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].Name != "A" {
+		t.Errorf("expected only A, got %+v", result.Functions)
+	}
+
+	calls = 0
+	var out strings.Builder
+	if err := StreamDir(dir, Options{Process: process}, &out); err != nil {
+		t.Fatalf("StreamDir failed: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected Process to run once per file, got %d calls", calls)
+	}
+	if !strings.Contains(out.String(), `"name":"A"`) || strings.Contains(out.String(), `"name":"C"`) {
+		t.Errorf("expected the processed lines to list only A, got:\n%s", out.String())
+	}
+}
+
+func TestStreamDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a.go":          "package app\n\nimport \"os\"\n\nfunc A1() { os.Remove(\"x\") }\n\nfunc A2() {}\n",
+		"a_test.go":     "package app\n\nfunc TestA() {}\n",
+		"sub/b.go":      "package sub\n\ntype B struct{}\n\nfunc (b B) Run() {}\n\nfunc helper() {}\n",
+		"bad/bad.go":    "package",
+		"vendor/v/v.go": "package v\n\nfunc V() {}\n",
+	})
+
+	var failed []string
+	var out flushCounter
+	if err := StreamDir(dir, Options{SkipTests: true, ExportedOnly: true, Workers: 2, FileError: recordFailures(&failed)}, &out); err != nil {
+		t.Fatalf("StreamDir failed: %v", err)
+	}
+	if len(failed) != 1 || filepath.Base(failed[0]) != "bad.go" {
+		t.Errorf("expected bad.go reported as failed, got %v", failed)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 || out.flushes != 2 {
+		t.Fatalf("expected 2 lines, each flushed, got %d lines and %d flushes:\n%s", len(lines), out.flushes, out.String())
+	}
+	byFile := make(map[string]string)
+	for _, line := range lines {
+		var result ExtractResult
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("decoding line failed: %v", err)
+		}
+		var names []string
+		for _, fn := range result.Functions {
+			names = append(names, fn.Name)
+		}
+		file := filepath.Base(result.Functions[0].File)
+		byFile[file] = strings.Join(names, ",")
+		if file == "a.go" && (result.Functions[0].IgnoredErrors != 1 || strings.Join(result.Dependencies, ",") != "os") {
+			t.Errorf("expected a.go analyzed with 1 ignored error and dependency os, got %d and %v", result.Functions[0].IgnoredErrors, result.Dependencies)
+		}
+		if file == "b.go" && strings.Join(result.Structs[0].Methods, ",") != "Run" {
+			t.Errorf("expected B to have method Run, got %v", result.Structs[0].Methods)
+		}
+		if result.Structs == nil {
+			t.Errorf("expected non-nil structs in each line")
+		}
+	}
+	if byFile["a.go"] != "A1,A2" || byFile["b.go"] != "Run" {
+		t.Errorf("expected a.go: A1,A2 and b.go: Run, got %v", byFile)
+	}
+}
+
+func TestStreamDirNotADirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "a.go")
+	os.WriteFile(file, []byte("package a\n"), 0644)
+
+	var out strings.Builder
+	if err := StreamDir(file, Options{}, &out); err == nil {
+		t.Errorf("expected an error streaming a file, got none")
+	}
+}
+
+func TestStreamDirFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"app/main.go":      "package main\n\nfunc main() {}\n",
+		"shared/shared.go": "package shared\n\nfunc Shared() {}\n",
+	})
+	if err := os.Symlink(filepath.Join(dir, "shared"), filepath.Join(dir, "app", "lib")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "shared"), filepath.Join(dir, "app", "lib2")); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	if err := StreamDir(filepath.Join(dir, "app"), Options{FollowSymlinks: true, Workers: 1}, &out); err != nil {
+		t.Fatalf("StreamDir failed: %v", err)
+	}
+	// lib2 leads to the directory already walked through lib.
+	if lines := strings.Count(out.String(), "\n"); lines != 2 {
+		t.Errorf("expected 2 lines, got %d:\n%s", lines, out.String())
+	}
+}
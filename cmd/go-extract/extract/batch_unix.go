@@ -0,0 +1,24 @@
+//go:build unix
+
+package extract
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// inode identifies a file by device and inode number.
+type inode struct {
+	dev, ino uint64
+}
+
+// dirID returns the device and inode of the directory at path, described by
+// info, to tell directories reached through different paths apart.
+func dirID(path string, info os.FileInfo) (any, error) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil, fmt.Errorf("%s: no inode information", path)
+	}
+	return inode{uint64(st.Dev), uint64(st.Ino)}, nil
+}
@@ -0,0 +1,55 @@
+package extract
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// knownOS and knownArch mirror the GOOS and GOARCH values go/build
+// recognizes in file name suffixes.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true,
+		"js": true, "linux": true, "nacl": true, "netbsd": true,
+		"openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true,
+		"armbe": true, "arm64": true, "arm64be": true, "loong64": true,
+		"mips": true, "mipsle": true, "mips64": true, "mips64le": true,
+		"mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true,
+		"s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+// fileOSArch derives GOOS and GOARCH from a file name's suffixes the way
+// go/build does (name_GOOS.go, name_GOARCH.go, name_GOOS_GOARCH.go, each
+// optionally followed by _test), without evaluating //go:build lines.
+// Either value is empty when the name doesn't constrain it.
+func fileOSArch(filename string) (goos, goarch string) {
+	name := strings.TrimSuffix(filepath.Base(filename), ".go")
+	// Like go/build, ignore everything before the first underscore so that
+	// linux.go or amd64.go on their own are unconstrained.
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return "", ""
+	}
+	parts := strings.Split(name[i:], "_")
+	if n := len(parts); n > 0 && parts[n-1] == "test" {
+		parts = parts[:n-1]
+	}
+	n := len(parts)
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return parts[n-2], parts[n-1]
+	}
+	if n >= 1 && knownOS[parts[n-1]] {
+		return parts[n-1], ""
+	}
+	if n >= 1 && knownArch[parts[n-1]] {
+		return "", parts[n-1]
+	}
+	return "", ""
+}
@@ -0,0 +1,24 @@
+package extract
+
+import "testing"
+
+func TestFileOSArch(t *testing.T) {
+	tests := []struct {
+		file, goos, goarch string
+	}{
+		{"foo_linux_amd64.go", "linux", "amd64"},
+		{"/src/foo_windows.go", "windows", ""},
+		{"foo_arm64.go", "", "arm64"},
+		{"foo_darwin_test.go", "darwin", ""},
+		{"foo_linux_amd64_test.go", "linux", "amd64"},
+		{"linux.go", "", ""},
+		{"foo.go", "", ""},
+		{"foo_bar.go", "", ""},
+	}
+	for _, tt := range tests {
+		goos, goarch := fileOSArch(tt.file)
+		if goos != tt.goos || goarch != tt.goarch {
+			t.Errorf("%s: expected %q/%q, got %q/%q", tt.file, tt.goos, tt.goarch, goos, goarch)
+		}
+	}
+}
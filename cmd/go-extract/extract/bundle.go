@@ -0,0 +1,69 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// bundleMarker starts a section of a -bundle file, naming the original file
+// the section came from.
+var bundleMarker = regexp.MustCompile(`^//\s*FILE:\s*(\S+)\s*$`)
+
+// bundleSection is one original file recovered from a bundle.
+type bundleSection struct {
+	path string
+	src  []byte
+}
+
+// splitBundle splits a bundled source on its // FILE: path.go marker lines.
+// Each section runs from the line after its marker to the line before the
+// next one, so line numbers extracted from it are relative to the section.
+// Anything before the first marker is dropped.
+func splitBundle(data []byte) []bundleSection {
+	var sections []bundleSection
+	var current *bundleSection
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if m := bundleMarker.FindSubmatch(bytes.TrimRight(line, "\r\n")); m != nil {
+			sections = append(sections, bundleSection{path: string(m[1])})
+			current = &sections[len(sections)-1]
+			continue
+		}
+		if current != nil {
+			current.src = append(current.src, line...)
+		}
+	}
+	return sections
+}
+
+// ExtractBundles extracts every section of each bundle in paths as its own
+// file, with File set to the path named by the section's marker, then runs
+// the cross-file analyses over the combined result. Unreadable bundles,
+// bundles without markers, and sections that fail to parse are reported to
+// opts.FileError and skipped.
+func ExtractBundles(paths []string, opts Options) *ExtractResult {
+	combined := newResult()
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			opts.fileError(path, fmt.Errorf("reading bundle: %w", err))
+			continue
+		}
+		sections := splitBundle(data)
+		if len(sections) == 0 {
+			opts.fileError(path, fmt.Errorf("no // FILE: markers in bundle"))
+			continue
+		}
+		for _, section := range sections {
+			result, err := extractBytes(section.path, section.src, opts)
+			if err != nil {
+				opts.fileError(section.path, err)
+				continue
+			}
+			combined.merge(result)
+		}
+	}
+	analyze(combined, opts)
+	return combined
+}
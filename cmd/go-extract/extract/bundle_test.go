@@ -0,0 +1,54 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractBundles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bundle.go.txt")
+	os.WriteFile(path, []byte(`// generated bundle
+// FILE: server/server.go
+package server
+
+type Server struct{}
+// FILE: server/start.go
+package server
+
+// Start starts.
+func (s *Server) Start() {}
+//FILE:  broken/bad.go  
+package
+`), 0644)
+
+	var failed []string
+	missing := filepath.Join(dir, "missing.txt")
+	result := ExtractBundles([]string{path, missing}, Options{FileError: recordFailures(&failed)})
+
+	if len(failed) != 2 || failed[0] != "broken/bad.go" || failed[1] != missing {
+		t.Errorf("expected the broken section and missing bundle to be reported, got %v", failed)
+	}
+	if len(result.Structs) != 1 || result.Structs[0].File != "server/server.go" || result.Structs[0].Line != 3 {
+		t.Errorf("expected Server at server/server.go:3, got %+v", result.Structs)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].File != "server/start.go" || result.Functions[0].Line != 4 {
+		t.Errorf("expected Start at server/start.go:4, got %+v", result.Functions)
+	}
+	if len(result.Structs) == 1 && len(result.Structs[0].Methods) != 1 {
+		t.Errorf("expected Start attached across sections, got %v", result.Structs[0].Methods)
+	}
+}
+
+func TestExtractBundlesWithoutMarkers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plain.go")
+	os.WriteFile(path, []byte("package main\n\nfunc Run() {}\n"), 0644)
+
+	var failed []string
+	result := ExtractBundles([]string{path}, Options{FileError: recordFailures(&failed)})
+	if len(failed) != 1 || len(result.Functions) != 0 {
+		t.Errorf("expected a markerless bundle to be reported and skipped, got %+v", result.Functions)
+	}
+}
@@ -0,0 +1,183 @@
+package extract
+
+import (
+	"go/ast"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// CallEdge is one edge of the -format callgraph output: a function calling a
+// top-level function found among the scanned files.
+type CallEdge struct {
+	From         string `json:"from"`
+	FromID       string `json:"from_id"`
+	FromFile     string `json:"from_file"`
+	To           string `json:"to"`
+	ToID         string `json:"to_id"`
+	ToFile       string `json:"to_file"`
+	CrossPackage bool   `json:"cross_package"`
+}
+
+// callTargets returns the distinct top-level functions body may call, in
+// first-seen order: "pkg/path.Func" for a selector on an imported package,
+// resolved through renamed imports, and the bare name for any other
+// identifier call except builtins. Bare names may be local variables or
+// conversions; buildCallGraph drops those it cannot resolve. Method calls
+// and calls of dot-imported names are left out.
+func callTargets(body *ast.BlockStmt, scope *fileScope) []string {
+	targets := []string{}
+	if body == nil {
+		return targets
+	}
+	seen := make(map[string]bool)
+	add := func(target string) {
+		if !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			if !builtinFuncs[fun.Name] && (scope.funcs[fun.Name] || !scope.dotImported(fun.Name)) {
+				add(fun.Name)
+			}
+		case *ast.SelectorExpr:
+			if x, ok := fun.X.(*ast.Ident); ok {
+				if path, imported := scope.imports[x.Name]; imported {
+					add(path + "." + fun.Sel.Name)
+				}
+			}
+		}
+		return true
+	})
+	return targets
+}
+
+// scannedPackage identifies a package among the scanned files by directory
+// and package name.
+type scannedPackage struct {
+	dir, name string
+}
+
+// funcIndex resolves call targets, as recorded by callTargets, to the
+// top-level functions of the scanned files.
+type funcIndex struct {
+	funcs    map[scannedPackage]map[string]FunctionInfo
+	resolved map[string]*scannedPackage
+}
+
+// newFuncIndex indexes the top-level functions of result by package.
+func newFuncIndex(result *ExtractResult) *funcIndex {
+	ix := &funcIndex{
+		funcs:    make(map[scannedPackage]map[string]FunctionInfo),
+		resolved: make(map[string]*scannedPackage),
+	}
+	for _, fn := range result.Functions {
+		if fn.Receiver != "" || fn.IsFuncLit {
+			continue
+		}
+		pkg := scannedPackage{filepath.Dir(fn.File), fn.Package}
+		if ix.funcs[pkg] == nil {
+			ix.funcs[pkg] = make(map[string]FunctionInfo)
+		}
+		ix.funcs[pkg][fn.Name] = fn
+	}
+	return ix
+}
+
+// lookup returns the function target names when called from package from.
+// Bare names resolve within from. A qualified name resolves to the scanned
+// package whose name matches the import path's last element and whose
+// directory shares the most trailing path elements with it, so
+// internal/store and vendor copies of other store packages are told apart;
+// ties are ambiguous and don't resolve.
+func (ix *funcIndex) lookup(from scannedPackage, target string) (FunctionInfo, bool) {
+	to, name := &from, target
+	if i := strings.LastIndex(target, "."); i >= 0 {
+		to, name = ix.resolve(target[:i]), target[i+1:]
+		if to == nil {
+			return FunctionInfo{}, false
+		}
+	}
+	fn, ok := ix.funcs[*to][name]
+	return fn, ok
+}
+
+// resolve returns the scanned package an import path refers to, or nil.
+func (ix *funcIndex) resolve(path string) *scannedPackage {
+	if pkg, ok := ix.resolved[path]; ok {
+		return pkg
+	}
+	var best *scannedPackage
+	bestScore, tie := 0, false
+	for pkg := range ix.funcs {
+		if pkg.name != importName(path) {
+			continue
+		}
+		score := trailingMatch(filepath.ToSlash(pkg.dir), path)
+		switch {
+		case score > bestScore:
+			p := pkg
+			best, bestScore, tie = &p, score, false
+		case score == bestScore && score > 0:
+			tie = true
+		}
+	}
+	if tie {
+		best = nil
+	}
+	ix.resolved[path] = best
+	return best
+}
+
+// buildCallGraph resolves the Calls of every function against the top-level
+// functions of the scanned files, as funcIndex.lookup does. Calls into
+// packages that were not scanned produce no edge.
+func buildCallGraph(result *ExtractResult) []CallEdge {
+	ix := newFuncIndex(result)
+	edges := []CallEdge{}
+	for _, fn := range result.Functions {
+		from := scannedPackage{filepath.Dir(fn.File), fn.Package}
+		for _, target := range fn.Calls {
+			callee, ok := ix.lookup(from, target)
+			if !ok {
+				continue
+			}
+			edges = append(edges, CallEdge{
+				From:         qualifiedName(fn.Package, fn.Receiver, fn.Name),
+				FromID:       fn.ID,
+				FromFile:     fn.File,
+				To:           qualifiedName(callee.Package, callee.Name),
+				ToID:         callee.ID,
+				ToFile:       callee.File,
+				CrossPackage: scannedPackage{filepath.Dir(callee.File), callee.Package} != from,
+			})
+		}
+	}
+	return edges
+}
+
+// trailingMatch counts the trailing slash-separated elements dir and path
+// share, ignoring a major-version element like /v2 at the end of path.
+func trailingMatch(dir, path string) int {
+	d, p := strings.Split(dir, "/"), strings.Split(path, "/")
+	if len(p) > 1 && isMajorVersion(p[len(p)-1]) {
+		p = p[:len(p)-1]
+	}
+	n := 0
+	for n < len(d) && n < len(p) && d[len(d)-1-n] == p[len(p)-1-n] {
+		n++
+	}
+	return n
+}
+
+// WriteCallGraph writes the resolved call graph edges as JSON.
+func WriteCallGraph(w io.Writer, result *ExtractResult) error {
+	return WriteIndentedJSON(w, buildCallGraph(result))
+}
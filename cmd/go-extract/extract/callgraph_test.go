@@ -0,0 +1,78 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildCallGraph(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"cmd/app/main.go":      "package main\n\nimport (\n\t\"fmt\"\n\ts \"example.com/app/internal/store\"\n\t\"example.com/app/util\"\n)\n\nfunc main() {\n\tdb := s.Open()\n\tfmt.Println(db)\n\trun(util.Name())\n\tutil.Missing()\n}\n\nfunc run(name string) { _ = len(name) }\n",
+		"cmd/app/helpers.go":   "package main\n\nfunc (a *App) Serve() { run(\"x\") }\n",
+		"internal/store/db.go": "package store\n\nfunc Open() *DB { return newDB() }\n\nfunc newDB() *DB { return &DB{} }\n",
+		"vendor/store/db.go":   "package store\n\nfunc Open() {}\n",
+		"util/name.go":         "package util\n\nfunc Name() string { return \"app\" }\n",
+	})
+	paths := []string{
+		filepath.Join(dir, "cmd/app/main.go"),
+		filepath.Join(dir, "cmd/app/helpers.go"),
+		filepath.Join(dir, "internal/store/db.go"),
+		filepath.Join(dir, "vendor/store/db.go"),
+		filepath.Join(dir, "util/name.go"),
+	}
+
+	result := ExtractFiles(paths, Options{})
+	if got := strings.Join(result.Functions[0].Calls, ","); got != "example.com/app/internal/store.Open,fmt.Println,run,example.com/app/util.Name,example.com/app/util.Missing" {
+		t.Errorf("unexpected calls for main: %s", got)
+	}
+
+	var got []string
+	for _, e := range buildCallGraph(result) {
+		edge := e.From + "->" + e.To
+		if e.CrossPackage {
+			edge += " (cross)"
+		}
+		got = append(got, edge)
+		if e.To == "store.Open" && !strings.Contains(e.ToFile, "internal") {
+			t.Errorf("expected store.Open to resolve to internal/store, got %s", e.ToFile)
+		}
+	}
+	expected := "main.main->store.Open (cross),main.main->main.run,main.main->util.Name (cross),main.App.Serve->main.run,store.Open->store.newDB"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("expected edges %s, got %s", expected, strings.Join(got, ","))
+	}
+}
+
+func TestTrailingMatch(t *testing.T) {
+	tests := []struct {
+		dir, path string
+		want      int
+	}{
+		{"/src/app/internal/store", "example.com/app/internal/store", 3},
+		{"/src/vendor/store", "example.com/app/internal/store", 1},
+		{"/src/mod/store", "example.com/mod/store/v2", 2},
+		{"/src/other", "example.com/store", 0},
+	}
+	for _, tt := range tests {
+		if got := trailingMatch(tt.dir, tt.path); got != tt.want {
+			t.Errorf("%s vs %s: expected %d, got %d", tt.dir, tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestCallTargetsSkipsBuiltinsAndMethods(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "calls.go")
+	os.WriteFile(src, []byte("package main\n\nfunc Run(s *Server, f func()) {\n\t_ = len(\"x\")\n\ts.Start()\n\tf()\n\tf()\n}\n"), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if got := strings.Join(result.Functions[0].Calls, ","); got != "f" {
+		t.Errorf("expected only the unresolved candidate f, got %s", got)
+	}
+}
@@ -0,0 +1,156 @@
+package extract
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+)
+
+// MetricFunc computes a -metric value for one function.
+type MetricFunc func(fn *FunctionInfo) float64
+
+// CompileMetric compiles a -metric expression such as "LOC*2+Complexity" into
+// a MetricFunc. Expressions use Go syntax restricted to number literals,
+// parentheses, unary + and -, the binary operators + - * / %, and names of
+// FunctionInfo's numeric and boolean fields (booleans count as 0 or 1),
+// including Halstead sub-fields as Halstead.Volume. Unknown names are
+// reported here rather than per function. Division or remainder by zero
+// yields 0 so the output stays valid JSON.
+func CompileMetric(expr string) (MetricFunc, error) {
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing -metric: %w", err)
+	}
+	return compileMetricNode(node)
+}
+
+// compileMetricNode compiles one node of a -metric expression.
+func compileMetricNode(node ast.Expr) (MetricFunc, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT {
+			return nil, fmt.Errorf("unsupported literal %s", n.Value)
+		}
+		v, err := strconv.ParseFloat(n.Value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", n.Value)
+		}
+		return func(*FunctionInfo) float64 { return v }, nil
+	case *ast.ParenExpr:
+		return compileMetricNode(n.X)
+	case *ast.Ident, *ast.SelectorExpr:
+		return compileMetricField(n)
+	case *ast.UnaryExpr:
+		x, err := compileMetricNode(n.X)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case token.ADD:
+			return x, nil
+		case token.SUB:
+			return func(fn *FunctionInfo) float64 { return -x(fn) }, nil
+		}
+		return nil, fmt.Errorf("unsupported operator %s", n.Op)
+	case *ast.BinaryExpr:
+		x, err := compileMetricNode(n.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := compileMetricNode(n.Y)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case token.ADD:
+			return func(fn *FunctionInfo) float64 { return x(fn) + y(fn) }, nil
+		case token.SUB:
+			return func(fn *FunctionInfo) float64 { return x(fn) - y(fn) }, nil
+		case token.MUL:
+			return func(fn *FunctionInfo) float64 { return x(fn) * y(fn) }, nil
+		case token.QUO:
+			return func(fn *FunctionInfo) float64 {
+				d := y(fn)
+				if d == 0 {
+					return 0
+				}
+				return x(fn) / d
+			}, nil
+		case token.REM:
+			return func(fn *FunctionInfo) float64 {
+				d := int64(y(fn))
+				if d == 0 {
+					return 0
+				}
+				return float64(int64(x(fn)) % d)
+			}, nil
+		}
+		return nil, fmt.Errorf("unsupported operator %s", n.Op)
+	}
+	return nil, fmt.Errorf("unsupported expression %T", node)
+}
+
+// compileMetricField resolves a field name (LOC) or Halstead sub-field
+// (Halstead.Volume) against FunctionInfo and returns a reader for it.
+func compileMetricField(node ast.Expr) (MetricFunc, error) {
+	var path []string
+	for node != nil {
+		switch n := node.(type) {
+		case *ast.Ident:
+			path = append([]string{n.Name}, path...)
+			node = nil
+		case *ast.SelectorExpr:
+			path = append([]string{n.Sel.Name}, path...)
+			node = n.X
+		default:
+			return nil, fmt.Errorf("unsupported expression %T", n)
+		}
+	}
+
+	typ := reflect.TypeOf(FunctionInfo{})
+	var index []int
+	for _, name := range path {
+		if typ.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("unknown field %s", name)
+		}
+		field, ok := typ.FieldByName(name)
+		if !ok || !field.IsExported() {
+			return nil, fmt.Errorf("unknown field %s", name)
+		}
+		index = append(index, field.Index...)
+		typ = field.Type
+	}
+
+	var read func(reflect.Value) float64
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		read = func(v reflect.Value) float64 { return float64(v.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		read = func(v reflect.Value) float64 { return float64(v.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		read = func(v reflect.Value) float64 { return v.Float() }
+	case reflect.Bool:
+		read = func(v reflect.Value) float64 {
+			if v.Bool() {
+				return 1
+			}
+			return 0
+		}
+	default:
+		return nil, fmt.Errorf("field %s is not numeric", typ.Name())
+	}
+	return func(fn *FunctionInfo) float64 {
+		return read(reflect.ValueOf(fn).Elem().FieldByIndex(index))
+	}, nil
+}
+
+// ApplyMetric sets each function's CustomMetric to metric's value for it.
+func ApplyMetric(result *ExtractResult, metric MetricFunc) {
+	for i := range result.Functions {
+		v := metric(&result.Functions[i])
+		result.Functions[i].CustomMetric = &v
+	}
+}
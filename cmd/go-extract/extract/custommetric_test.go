@@ -0,0 +1,49 @@
+package extract
+
+import "testing"
+
+func TestCompileMetric(t *testing.T) {
+	fn := &FunctionInfo{LOC: 10, Complexity: 3, NeverReturns: true, Halstead: HalsteadMetrics{Volume: 2.5}}
+
+	tests := map[string]float64{
+		"LOC*2+Complexity":          23,
+		"-(LOC - Complexity) / 2":   -3.5,
+		"LOC % 4":                   2,
+		"Halstead.Volume * 4":       10,
+		"NeverReturns + IsVariadic": 1,
+		"LOC / (Complexity - 3)":    0,
+	}
+	for expr, want := range tests {
+		metric, err := CompileMetric(expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", expr, err)
+			continue
+		}
+		if got := metric(fn); got != want {
+			t.Errorf("%s: expected %v, got %v", expr, want, got)
+		}
+	}
+
+	for _, bad := range []string{"LOC +", "Missing * 2", "Name", "LOC == 1", `"x"`, "f(LOC)", "Halstead"} {
+		if _, err := CompileMetric(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestApplyMetric(t *testing.T) {
+	result := &ExtractResult{Functions: []FunctionInfo{{LOC: 4}, {LOC: 0}}}
+	metric, err := CompileMetric("LOC * 1.5")
+	if err != nil {
+		t.Fatalf("compileMetric failed: %v", err)
+	}
+
+	ApplyMetric(result, metric)
+
+	if m := result.Functions[0].CustomMetric; m == nil || *m != 6 {
+		t.Errorf("expected custom metric 6, got %v", m)
+	}
+	if m := result.Functions[1].CustomMetric; m == nil || *m != 0 {
+		t.Errorf("expected custom metric 0 to be set, got %v", m)
+	}
+}
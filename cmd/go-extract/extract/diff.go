@@ -0,0 +1,193 @@
+package extract
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DiffResult reports how the symbols of two extraction results differ.
+type DiffResult struct {
+	Functions  EntityDiff `json:"functions"`
+	Structs    EntityDiff `json:"structs"`
+	Interfaces EntityDiff `json:"interfaces"`
+}
+
+// EntityDiff lists the symbols of one kind that were added, removed, or changed.
+type EntityDiff struct {
+	Added   []string        `json:"added"`
+	Removed []string        `json:"removed"`
+	Changed []ChangedEntity `json:"changed"`
+}
+
+// ChangedEntity describes a symbol present in both results whose size,
+// signature, or body differs.
+type ChangedEntity struct {
+	Name             string `json:"name"`
+	LOCDelta         int    `json:"loc_delta"`
+	SignatureChanged bool   `json:"signature_changed"`
+	BodyChanged      bool   `json:"body_changed"`
+	OldSignature     string `json:"old_signature"`
+	NewSignature     string `json:"new_signature"`
+}
+
+// diffSymbol is the comparable view of one extracted entity.
+type diffSymbol struct {
+	loc       int
+	signature string
+	body      string
+}
+
+// DiffFiles loads two extraction JSON files and diffs them.
+func DiffFiles(oldPath, newPath string) (*DiffResult, error) {
+	oldResult, err := loadResult(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newResult, err := loadResult(newPath)
+	if err != nil {
+		return nil, err
+	}
+	return diffResults(oldResult, newResult), nil
+}
+
+// loadResult reads an ExtractResult previously written as JSON.
+func loadResult(path string) (*ExtractResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var result ExtractResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// diffResults compares two extraction results. Symbols are matched by
+// directory, package, receiver, name, and GOOS/GOARCH build constraint, so a
+// function that moves between lines, or between files of its directory, is
+// not reported. Symbols sharing all of these, such as several init functions,
+// are matched by their order of appearance. A matched symbol is changed when
+// its LOC or its signature differs: parameter and result types for
+// functions, fields and embeds for structs, and method names for interfaces.
+// Functions are also changed when their body text differs.
+func diffResults(oldResult, newResult *ExtractResult) *DiffResult {
+	return &DiffResult{
+		Functions:  diffSymbols(functionSymbols(oldResult), functionSymbols(newResult)),
+		Structs:    diffSymbols(structSymbols(oldResult), structSymbols(newResult)),
+		Interfaces: diffSymbols(interfaceSymbols(oldResult), interfaceSymbols(newResult)),
+	}
+}
+
+// qualifiedName joins the non-empty parts of a symbol's identity with dots,
+// e.g. "server.Server.Start".
+func qualifiedName(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, ".")
+}
+
+// symbolKeys builds the names diffed symbols are keyed by: the qualified
+// name, prefixed with the declaring file's directory when there is one and
+// suffixed with any GOOS/GOARCH constraint in brackets, as in
+// "internal/util/util.Helper [linux]". A name already taken by an earlier
+// symbol gets its occurrence number, so the second init of a package is
+// "app/main.init#2".
+type symbolKeys map[string]int
+
+// key returns the next key for a symbol declared in file.
+func (k symbolKeys) key(file, goos, goarch string, parts ...string) string {
+	name := qualifiedName(parts...)
+	if dir := filepath.ToSlash(filepath.Dir(file)); file != "" && dir != "." {
+		name = dir + "/" + name
+	}
+	if constraint := qualifiedName(goos, goarch); constraint != "" {
+		name += " [" + strings.ReplaceAll(constraint, ".", "/") + "]"
+	}
+	k[name]++
+	if n := k[name]; n > 1 {
+		name += "#" + strconv.Itoa(n)
+	}
+	return name
+}
+
+// functionSymbols keys functions by qualified name.
+func functionSymbols(result *ExtractResult) map[string]diffSymbol {
+	symbols := make(map[string]diffSymbol, len(result.Functions))
+	keys := make(symbolKeys)
+	for _, fn := range result.Functions {
+		sig := "(" + strings.Join(fn.ParamTypes, ", ") + ")"
+		if len(fn.Results) > 0 {
+			sig += " (" + strings.Join(fn.Results, ", ") + ")"
+		}
+		symbols[keys.key(fn.File, fn.GOOS, fn.GOARCH, fn.Package, fn.Receiver, fn.Name)] = diffSymbol{fn.LOC, sig, fn.Body}
+	}
+	return symbols
+}
+
+// structSymbols keys structs by qualified name.
+func structSymbols(result *ExtractResult) map[string]diffSymbol {
+	symbols := make(map[string]diffSymbol, len(result.Structs))
+	keys := make(symbolKeys)
+	for _, s := range result.Structs {
+		sig := "{" + strings.Join(append(append([]string{}, s.Embedded...), s.Fields...), "; ") + "}"
+		symbols[keys.key(s.File, s.GOOS, s.GOARCH, s.Package, s.Name)] = diffSymbol{s.LOC, sig, ""}
+	}
+	return symbols
+}
+
+// interfaceSymbols keys interfaces by qualified name. Interfaces carry no
+// LOC, so only their method sets are compared.
+func interfaceSymbols(result *ExtractResult) map[string]diffSymbol {
+	symbols := make(map[string]diffSymbol, len(result.Interfaces))
+	keys := make(symbolKeys)
+	for _, iface := range result.Interfaces {
+		sig := "{" + strings.Join(iface.Methods, "; ") + "}"
+		symbols[keys.key(iface.File, iface.GOOS, iface.GOARCH, iface.Package, iface.Name)] = diffSymbol{0, sig, ""}
+	}
+	return symbols
+}
+
+// diffSymbols compares two symbol sets, reporting names in sorted order.
+func diffSymbols(oldSymbols, newSymbols map[string]diffSymbol) EntityDiff {
+	diff := EntityDiff{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []ChangedEntity{},
+	}
+	for name, n := range newSymbols {
+		o, ok := oldSymbols[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+		if o.loc != n.loc || o.signature != n.signature || o.body != n.body {
+			diff.Changed = append(diff.Changed, ChangedEntity{
+				Name:             name,
+				LOCDelta:         n.loc - o.loc,
+				SignatureChanged: o.signature != n.signature,
+				BodyChanged:      o.body != n.body,
+				OldSignature:     o.signature,
+				NewSignature:     n.signature,
+			})
+		}
+	}
+	for name := range oldSymbols {
+		if _, ok := newSymbols[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff
+}
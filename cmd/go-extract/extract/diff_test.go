@@ -0,0 +1,113 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	oldResult := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "Start", Package: "server", Receiver: "Server", LOC: 10, ParamTypes: []string{}},
+			{Name: "Stop", Package: "server", Receiver: "Server", LOC: 5, ParamTypes: []string{}},
+			{Name: "Old", Package: "server", LOC: 3, ParamTypes: []string{}},
+		},
+		Structs: []StructInfo{
+			{Name: "Server", Package: "server", LOC: 4, Fields: []string{"Host"}},
+		},
+	}
+	newResult := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "Start", Package: "server", Receiver: "Server", LOC: 10, ParamTypes: []string{"context.Context"}},
+			{Name: "Stop", Package: "server", Receiver: "Server", LOC: 8, ParamTypes: []string{}},
+			{Name: "New", Package: "server", LOC: 3, ParamTypes: []string{}},
+		},
+		Structs: []StructInfo{
+			{Name: "Server", Package: "server", LOC: 4, Fields: []string{"Host"}},
+		},
+	}
+
+	diff := diffResults(oldResult, newResult)
+
+	if len(diff.Functions.Added) != 1 || diff.Functions.Added[0] != "server.New" {
+		t.Errorf("expected server.New added, got %v", diff.Functions.Added)
+	}
+	if len(diff.Functions.Removed) != 1 || diff.Functions.Removed[0] != "server.Old" {
+		t.Errorf("expected server.Old removed, got %v", diff.Functions.Removed)
+	}
+	if len(diff.Functions.Changed) != 2 {
+		t.Fatalf("expected 2 changed functions, got %v", diff.Functions.Changed)
+	}
+	start, stop := diff.Functions.Changed[0], diff.Functions.Changed[1]
+	if start.Name != "server.Server.Start" || !start.SignatureChanged || start.LOCDelta != 0 {
+		t.Errorf("expected Start signature change, got %+v", start)
+	}
+	if stop.Name != "server.Server.Stop" || stop.SignatureChanged || stop.LOCDelta != 3 {
+		t.Errorf("expected Stop LOC delta of 3, got %+v", stop)
+	}
+	if len(diff.Structs.Changed) != 0 || len(diff.Structs.Added) != 0 {
+		t.Errorf("expected unchanged structs, got %+v", diff.Structs)
+	}
+}
+
+func TestDiffResultsDuplicateNames(t *testing.T) {
+	oldResult := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "init", File: "app/a.go", Package: "main", LOC: 1, Body: "{}"},
+			{Name: "init", File: "app/b.go", Package: "main", LOC: 1, Body: "{}"},
+			{Name: "Helper", File: "internal/util/util.go", Package: "util", LOC: 1},
+			{Name: "Helper", File: "pkg/util/util.go", Package: "util", LOC: 1},
+			{Name: "open", File: "fs/open_linux.go", Package: "fs", GOOS: "linux", LOC: 1},
+			{Name: "open", File: "fs/open_windows.go", Package: "fs", GOOS: "windows", LOC: 1},
+		},
+	}
+	newResult := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "init", File: "app/a.go", Package: "main", LOC: 1, Body: "{}"},
+			{Name: "init", File: "app/b.go", Package: "main", LOC: 1, Body: "{ println(2) }"},
+			{Name: "Helper", File: "internal/util/util.go", Package: "util", LOC: 1},
+			{Name: "Helper", File: "pkg/util/util.go", Package: "util", LOC: 2},
+			{Name: "open", File: "fs/open_linux.go", Package: "fs", GOOS: "linux", LOC: 1},
+		},
+	}
+
+	diff := diffResults(oldResult, newResult)
+
+	if len(diff.Functions.Changed) != 2 {
+		t.Fatalf("expected 2 changed functions, got %+v", diff.Functions.Changed)
+	}
+	init2, helper := diff.Functions.Changed[0], diff.Functions.Changed[1]
+	if init2.Name != "app/main.init#2" || !init2.BodyChanged || init2.LOCDelta != 0 {
+		t.Errorf("expected the second init's body change, got %+v", init2)
+	}
+	if helper.Name != "pkg/util/util.Helper" || helper.LOCDelta != 1 {
+		t.Errorf("expected pkg/util's Helper LOC delta of 1, got %+v", helper)
+	}
+	if len(diff.Functions.Removed) != 1 || diff.Functions.Removed[0] != "fs/fs.open [windows]" {
+		t.Errorf("expected the windows open removed, got %v", diff.Functions.Removed)
+	}
+	if len(diff.Functions.Added) != 0 {
+		t.Errorf("expected no added functions, got %v", diff.Functions.Added)
+	}
+}
+
+func TestDiffFilesRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.json")
+	newPath := filepath.Join(dir, "new.json")
+	os.WriteFile(oldPath, []byte(`{"functions":[{"name":"A","loc":1}]}`), 0644)
+	os.WriteFile(newPath, []byte(`{"functions":[{"name":"B","loc":1}]}`), 0644)
+
+	diff, err := DiffFiles(oldPath, newPath)
+	if err != nil {
+		t.Fatalf("diffFiles failed: %v", err)
+	}
+	if len(diff.Functions.Added) != 1 || len(diff.Functions.Removed) != 1 {
+		t.Errorf("expected one added and one removed function, got %+v", diff.Functions)
+	}
+
+	if _, err := DiffFiles(oldPath, filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
@@ -0,0 +1,109 @@
+package extract
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"hash"
+	"io"
+)
+
+// duplicateMinLines is the shortest function, in lines, reported by
+// -format duplicates. Shorter functions such as accessors and stubs match
+// each other trivially.
+const duplicateMinLines = 5
+
+// DuplicateGroup is a set of functions whose bodies are identical up to
+// identifier names, for -format duplicates.
+type DuplicateGroup struct {
+	Hash      string         `json:"hash"`
+	Functions []DuplicateRef `json:"functions"`
+}
+
+// DuplicateRef locates one function of a DuplicateGroup.
+type DuplicateRef struct {
+	Name     string `json:"name"`
+	Receiver string `json:"receiver,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	LOC      int    `json:"loc"`
+}
+
+// normalizedBodyHash returns the hex SHA-256 digest of the shape of body:
+// its node types, operators, and literals in tree order, with every
+// identifier reduced to a placeholder. Comments and formatting never reach
+// the AST walk, so bodies that differ only in names, layout, or comments hash
+// the same. Functions without a body hash to the empty string.
+func normalizedBodyHash(body *ast.BlockStmt) string {
+	if body == nil {
+		return ""
+	}
+	h := sha256.New()
+	ast.Inspect(body, func(n ast.Node) bool {
+		writeNodeShape(h, n)
+		return true
+	})
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeNodeShape writes the part of n that normalizedBodyHash keeps. The nil
+// node ast.Inspect passes after a node's children closes it, so the nesting
+// is part of the shape.
+func writeNodeShape(h hash.Hash, n ast.Node) {
+	switch n := n.(type) {
+	case nil:
+		io.WriteString(h, ")")
+		return
+	case *ast.BasicLit:
+		fmt.Fprintf(h, "(lit %s %s", n.Kind, n.Value)
+		return
+	case *ast.BinaryExpr:
+		fmt.Fprintf(h, "(binary %s", n.Op)
+		return
+	case *ast.UnaryExpr:
+		fmt.Fprintf(h, "(unary %s", n.Op)
+		return
+	case *ast.AssignStmt:
+		fmt.Fprintf(h, "(assign %s", n.Tok)
+		return
+	case *ast.IncDecStmt:
+		fmt.Fprintf(h, "(incdec %s", n.Tok)
+		return
+	case *ast.BranchStmt:
+		fmt.Fprintf(h, "(branch %s", n.Tok)
+		return
+	case *ast.RangeStmt:
+		fmt.Fprintf(h, "(range %s", n.Tok)
+		return
+	case *ast.GenDecl:
+		fmt.Fprintf(h, "(decl %s", n.Tok)
+		return
+	case *ast.ChanType:
+		fmt.Fprintf(h, "(chan %d", n.Dir)
+		return
+	}
+	fmt.Fprintf(h, "(%T", n)
+}
+
+// WriteDuplicates writes, as JSON, the groups of two or more functions of at
+// least duplicateMinLines lines that share a normalized body hash. Groups
+// appear in the order their first function was extracted.
+func WriteDuplicates(w io.Writer, result *ExtractResult) error {
+	groups := []DuplicateGroup{}
+	index := make(map[string]int)
+	for _, fn := range result.Functions {
+		if fn.NormalizedHash == "" || fn.LOC < duplicateMinLines {
+			continue
+		}
+		ref := DuplicateRef{Name: fn.Name, Receiver: fn.Receiver, File: fn.File, Line: fn.Line, LOC: fn.LOC}
+		i, ok := index[fn.NormalizedHash]
+		if !ok {
+			i = len(groups)
+			index[fn.NormalizedHash] = i
+			groups = append(groups, DuplicateGroup{Hash: fn.NormalizedHash})
+		}
+		groups[i].Functions = append(groups[i].Functions, ref)
+	}
+	return WriteIndentedJSON(w, keep(groups, func(g DuplicateGroup) bool { return len(g.Functions) > 1 }))
+}
@@ -0,0 +1,80 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizedBodyHash(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	os.WriteFile(a, []byte(`package main
+
+func Sum(xs []int) int {
+	total := 0
+	for _, x := range xs {
+		if x > 0 { total += x }
+	}
+	return total
+}
+
+func Product(xs []int) int {
+	total := 1
+	for _, x := range xs {
+		if x > 0 {
+			total *= x
+		}
+	}
+	return total
+}
+
+func Stub() {}
+`), 0644)
+	os.WriteFile(b, []byte(`package util
+
+// Add totals the positive values.
+func (c *Calc) Add(values []int) int {
+	acc := 0
+	for _, v := range values {
+		// skip negatives
+		if v > 0 {
+			acc += v
+		}
+	}
+	return acc
+}
+
+func asm()
+`), 0644)
+
+	result := ExtractFiles([]string{a, b}, Options{})
+	sum, product, stub, add, asm := result.Functions[0], result.Functions[1], result.Functions[2], result.Functions[3], result.Functions[4]
+	if sum.NormalizedHash != add.NormalizedHash {
+		t.Errorf("expected Sum and Add to share a normalized hash")
+	}
+	if sum.NormalizedHash == product.NormalizedHash {
+		t.Errorf("expected Sum and Product to differ in operator and literal")
+	}
+	if stub.NormalizedHash == "" || asm.NormalizedHash != "" {
+		t.Errorf("expected a hash for Stub and none for asm, got %q and %q", stub.NormalizedHash, asm.NormalizedHash)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDuplicates(&buf, result); err != nil {
+		t.Fatalf("writeDuplicates failed: %v", err)
+	}
+	var groups []DuplicateGroup
+	if err := json.Unmarshal(buf.Bytes(), &groups); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(groups) != 1 || len(groups[0].Functions) != 2 {
+		t.Fatalf("expected one group of two, got %+v", groups)
+	}
+	if g := groups[0].Functions; g[0].Name != "Sum" || g[1].Name != "Add" || g[1].Receiver != "Calc" {
+		t.Errorf("expected Sum and Calc.Add, got %+v", g)
+	}
+}
@@ -0,0 +1,183 @@
+package extract
+
+import (
+	"go/ast"
+	"path/filepath"
+)
+
+// discardedCall is a call whose results are dropped: used as a statement, or
+// assigned with some results to _.
+type discardedCall struct {
+	// target is the callee as callTargets names it, or "" for a method call.
+	target string
+	// method is the method name of a method call.
+	method string
+	// blanks lists the result positions assigned to _; nil when the call is
+	// a statement and every result is dropped.
+	blanks []int
+}
+
+// stdErrorFuncs gives the number of results, the last an error, of
+// commonly used standard library functions, for calls into packages that
+// were not scanned. fmt's print functions are deliberately absent: their
+// errors are conventionally ignored.
+var stdErrorFuncs = map[string]int{
+	"os.Chdir": 1, "os.Chmod": 1, "os.Chtimes": 1, "os.Mkdir": 1, "os.MkdirAll": 1,
+	"os.Remove": 1, "os.RemoveAll": 1, "os.Rename": 1, "os.Setenv": 1,
+	"os.Symlink": 1, "os.Unsetenv": 1, "os.WriteFile": 1,
+	"os.Create": 2, "os.Open": 2, "os.OpenFile": 2, "os.ReadFile": 2, "os.ReadDir": 2,
+	"io.Copy": 2, "io.CopyN": 2, "io.ReadAll": 2, "io.ReadFull": 2, "io.WriteString": 2,
+	"encoding/json.Unmarshal": 1, "encoding/json.Marshal": 2, "encoding/json.MarshalIndent": 2,
+	"strconv.Atoi": 2, "strconv.ParseBool": 2, "strconv.ParseFloat": 2,
+	"strconv.ParseInt": 2, "strconv.ParseUint": 2,
+	"net/http.ListenAndServe": 1, "net/http.ListenAndServeTLS": 1,
+}
+
+// stdErrorMethods lists method names that, on the types that commonly
+// declare them (files, connections, writers, transactions), return only an
+// error. They apply to method calls not resolved among the scanned methods.
+var stdErrorMethods = map[string]bool{
+	"Close": true, "Commit": true, "Flush": true, "Rollback": true,
+	"Shutdown": true, "Sync": true,
+}
+
+// discardedCalls returns the calls in body whose results are dropped.
+// Calls in go and defer statements are not included.
+func discardedCalls(body *ast.BlockStmt, scope *fileScope) []discardedCall {
+	var calls []discardedCall
+	if body == nil {
+		return calls
+	}
+	add := func(expr ast.Expr, blanks []int) {
+		call, ok := ast.Unparen(expr).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+		d := discardedCall{blanks: blanks}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			if builtinFuncs[fun.Name] || (!scope.funcs[fun.Name] && scope.dotImported(fun.Name)) {
+				return
+			}
+			d.target = fun.Name
+		case *ast.SelectorExpr:
+			if x, ok := fun.X.(*ast.Ident); ok {
+				if path, imported := scope.imports[x.Name]; imported {
+					d.target = path + "." + fun.Sel.Name
+					break
+				}
+			}
+			d.method = fun.Sel.Name
+		default:
+			return
+		}
+		calls = append(calls, d)
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch stmt := n.(type) {
+		case *ast.ExprStmt:
+			add(stmt.X, nil)
+		case *ast.AssignStmt:
+			if len(stmt.Rhs) != 1 {
+				return true
+			}
+			blanks := []int{}
+			for i, lhs := range stmt.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == "_" {
+					blanks = append(blanks, i)
+				}
+			}
+			if len(blanks) > 0 {
+				add(stmt.Rhs[0], blanks)
+			}
+		}
+		return true
+	})
+	return calls
+}
+
+// countIgnoredErrors sets each function's IgnoredErrors to the number of
+// its discarded calls that drop an error result. The callee's results come
+// from the scanned files where possible: top-level functions resolve as in
+// the call graph, and a method call uses the scanned methods of that name in
+// the caller's package when they all agree on which results are errors.
+// Otherwise stdErrorFuncs and stdErrorMethods are consulted, and calls
+// matching neither are assumed not to return an error. Without type
+// checking, results are only recognized as errors when declared as error,
+// methods are matched by name alone, and a call through a function value or
+// into an unscanned, unlisted package goes uncounted; errors dropped by
+// defer and go statements aren't counted either.
+func countIgnoredErrors(result *ExtractResult) {
+	ix := newFuncIndex(result)
+	methods := make(map[scannedPackage]map[string][][]string)
+	for _, fn := range result.Functions {
+		if fn.Receiver == "" {
+			continue
+		}
+		pkg := scannedPackage{filepath.Dir(fn.File), fn.Package}
+		if methods[pkg] == nil {
+			methods[pkg] = make(map[string][][]string)
+		}
+		methods[pkg][fn.Name] = append(methods[pkg][fn.Name], fn.Results)
+	}
+
+	for i, fn := range result.Functions {
+		from := scannedPackage{filepath.Dir(fn.File), fn.Package}
+		ignored := 0
+		for _, d := range fn.discards {
+			var candidates [][]string
+			if d.target != "" {
+				if callee, ok := ix.lookup(from, d.target); ok {
+					candidates = [][]string{callee.Results}
+				} else if n, ok := stdErrorFuncs[d.target]; ok {
+					candidates = [][]string{errorResults(n)}
+				}
+			} else if scanned, ok := methods[from][d.method]; ok {
+				candidates = scanned
+			} else if stdErrorMethods[d.method] {
+				candidates = [][]string{errorResults(1)}
+			}
+			if len(candidates) == 0 {
+				continue
+			}
+			drops := true
+			for _, results := range candidates {
+				drops = drops && dropsError(results, d.blanks)
+			}
+			if drops {
+				ignored++
+			}
+		}
+		result.Functions[i].IgnoredErrors = ignored
+	}
+}
+
+// errorResults returns n result types, the last of them error.
+func errorResults(n int) []string {
+	results := make([]string, n)
+	results[n-1] = "error"
+	return results
+}
+
+// dropsError reports whether discarding the results at blanks, or all of
+// them when blanks is nil, drops an error result.
+func dropsError(results []string, blanks []int) bool {
+	if blanks == nil {
+		for _, r := range results {
+			if r == "error" {
+				return true
+			}
+		}
+		return false
+	}
+	if len(blanks) > 0 && blanks[len(blanks)-1] >= len(results) {
+		// The assignment doesn't match this callee's results.
+		return false
+	}
+	for _, i := range blanks {
+		if results[i] == "error" {
+			return true
+		}
+	}
+	return false
+}
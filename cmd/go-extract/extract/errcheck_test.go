@@ -0,0 +1,71 @@
+package extract
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCountIgnoredErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"store/store.go": `package store
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type DB struct{}
+
+func (db *DB) Save() error { return nil }
+func (db *DB) Len() int { return 0 }
+
+func Open(name string) (*DB, error) { return &DB{}, nil }
+func Count() int { return 0 }
+
+func Run(db *DB, f *os.File) {
+	db.Save()                 // ignored: scanned method
+	_ = db.Save()             // ignored
+	db.Len()                  // fine: no error result
+	Open("x")                 // ignored: scanned function
+	d, _ := Open("y")         // ignored
+	_, err := Open("z")       // fine: error kept
+	Count()                   // fine
+	os.Remove("tmp")          // ignored: stdlib table
+	n, _ := strconv.Atoi("1") // ignored
+	f.Close()                 // ignored: stdlib method name
+	defer f.Close()           // not counted
+	fmt.Println("done")       // fine: conventionally ignored
+	_, _ = d, n
+	_ = err
+}
+
+func Clean() error {
+	return os.Remove("tmp")
+}
+`,
+		"app/app.go": `package app
+
+import "example.com/store"
+
+func Main() {
+	store.Open("a")
+	unknown.Do()
+}
+`,
+	})
+
+	result := ExtractFiles([]string{filepath.Join(dir, "store/store.go"), filepath.Join(dir, "app/app.go")}, Options{})
+
+	expected := map[string]int{"Run": 7, "Clean": 0, "Main": 1, "Save": 0}
+	for _, fn := range result.Functions {
+		want, ok := expected[fn.Name]
+		if !ok {
+			continue
+		}
+		if fn.IgnoredErrors != want {
+			t.Errorf("expected %s to ignore %d errors, got %d", fn.Name, want, fn.IgnoredErrors)
+		}
+	}
+}
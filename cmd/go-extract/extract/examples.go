@@ -0,0 +1,60 @@
+package extract
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/token"
+	"strings"
+)
+
+// ExampleInfo describes a testable example function (ExampleServer_Start)
+// and the output its trailing // Output: or // Unordered output: comment
+// expects.
+type ExampleInfo struct {
+	Name      string `json:"name"`
+	File      string `json:"file"`
+	Package   string `json:"package"`
+	Line      int    `json:"line"`
+	Doc       string `json:"doc"`
+	Output    string `json:"output"`
+	HasOutput bool   `json:"has_output"`
+	Unordered bool   `json:"unordered"`
+}
+
+// extractExamples returns the example functions of a _test.go file, as found
+// by go/doc: top-level functions named Example, ExampleF, ExampleT_M, or with
+// a lowercase _suffix, taking no parameters and returning nothing. Output is
+// the expected output with surrounding whitespace trimmed; HasOutput tells an
+// empty // Output: block, which asserts no output, from a missing one, which
+// means the example is compiled but not run. Other files yield none.
+func extractExamples(fset *token.FileSet, file *ast.File, filename string) []ExampleInfo {
+	examples := []ExampleInfo{}
+	if !strings.HasSuffix(filename, "_test.go") {
+		return examples
+	}
+	// doc.Examples sorts by name; report them in source order instead.
+	byName := make(map[string]*doc.Example)
+	for _, ex := range doc.Examples(file) {
+		byName["Example"+ex.Name] = ex
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil {
+			continue
+		}
+		ex, ok := byName[fn.Name.Name]
+		if !ok {
+			continue
+		}
+		examples = append(examples, ExampleInfo{
+			Name:      fn.Name.Name,
+			File:      filename,
+			Line:      fset.Position(fn.Pos()).Line,
+			Doc:       ex.Doc,
+			Output:    strings.TrimSpace(ex.Output),
+			HasOutput: ex.Output != "" || ex.EmptyOutput,
+			Unordered: ex.Unordered,
+		})
+	}
+	return examples
+}
@@ -0,0 +1,82 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractExamples(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "server_example_test.go")
+	os.WriteFile(src, []byte(`package server_test
+
+import "fmt"
+
+// ExampleServer_Start shows a start.
+func ExampleServer_Start() {
+	fmt.Println("started")
+	fmt.Println("ready")
+	// Output:
+	// started
+	// ready
+}
+
+func Example_unordered() {
+	fmt.Println("b")
+	fmt.Println("a")
+	// Unordered output: a
+	// b
+}
+
+func Example_silent() {
+	// Output:
+}
+
+func ExampleCompileOnly() {
+	fmt.Println("not run")
+}
+
+func Examplelower() {}
+
+func ExampleArgs(n int) {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Examples) != 4 {
+		t.Fatalf("expected 4 examples, got %+v", result.Examples)
+	}
+	start, unordered, silent, compileOnly := result.Examples[0], result.Examples[1], result.Examples[2], result.Examples[3]
+	if start.Name != "ExampleServer_Start" || start.Line != 6 || start.Package != "server_test" || start.Doc != "ExampleServer_Start shows a start.\n" {
+		t.Errorf("unexpected example %+v", start)
+	}
+	if start.Output != "started\nready" || !start.HasOutput || start.Unordered {
+		t.Errorf("expected ordered output started/ready, got %+v", start)
+	}
+	if unordered.Output != "a\nb" || !unordered.Unordered {
+		t.Errorf("expected unordered output a/b, got %+v", unordered)
+	}
+	if silent.Output != "" || !silent.HasOutput {
+		t.Errorf("expected an empty output block, got %+v", silent)
+	}
+	if compileOnly.HasOutput {
+		t.Errorf("expected no output block, got %+v", compileOnly)
+	}
+}
+
+func TestExtractExamplesOutsideTestFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "server.go")
+	os.WriteFile(src, []byte("package server\n\nfunc Example() {\n\t// Output: hi\n}\n"), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if result.Examples == nil || len(result.Examples) != 0 {
+		t.Errorf("expected no examples outside _test.go files, got %+v", result.Examples)
+	}
+}
@@ -0,0 +1,798 @@
+package extract
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ExtractFiles extracts every file in paths and combines the results, then
+// runs the cross-file analyses. Files that fail are reported to
+// opts.FileError and skipped. Each processed file is counted on
+// opts.Progress, if set.
+func ExtractFiles(paths []string, opts Options) *ExtractResult {
+	combined := newResult()
+	for _, path := range paths {
+		result, err := extractFile(path, opts)
+		opts.Progress.Increment()
+		if err != nil {
+			opts.fileError(path, err)
+			continue
+		}
+		combined.merge(result)
+	}
+	analyze(combined, opts)
+	return combined
+}
+
+// extractFile parses a Go source file and extracts functions, structs,
+// interfaces, and package-level values. With opts.Timeout set, a file taking
+// longer fails with an error instead.
+func extractFile(filename string, opts Options) (*ExtractResult, error) {
+	if opts.Timeout <= 0 {
+		return extractFileNow(filename, opts)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	return extractFileContext(ctx, filename, opts)
+}
+
+// extractFileContext runs extractFileNow in a goroutine and gives up when
+// ctx is done. The parser cannot be interrupted, so an abandoned extraction
+// keeps running in the background until it finishes; its result is dropped.
+func extractFileContext(ctx context.Context, filename string, opts Options) (*ExtractResult, error) {
+	type outcome struct {
+		result *ExtractResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := extractFileNow(filename, opts)
+		done <- outcome{result, err}
+	}()
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("extraction timed out: %w", ctx.Err())
+	}
+}
+
+// extractFileNow reads and extracts filename without a time limit.
+func extractFileNow(filename string, opts Options) (*ExtractResult, error) {
+	srcBytes, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+	if !opts.Template {
+		return extractBytes(filename, srcBytes, opts)
+	}
+	result, err := extractBytes(filename, stripTemplateActions(srcBytes), opts)
+	if err != nil {
+		return nil, err
+	}
+	// Hash the template as read, not the rewrite that was parsed. A skipped
+	// generated file lists no files.
+	if len(result.Files) > 0 {
+		result.Files[0].FileHash = fileHash(srcBytes)
+	}
+	return result, nil
+}
+
+// fileHash returns the hex SHA-256 digest of data.
+func fileHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// extractBytes extracts functions, structs, interfaces, and package-level
+// values from in-memory Go source. filename is used for positions and the
+// File field only. A leading UTF-8 byte order mark is stripped first, so
+// Offset fields count from the first byte after it; the file's FileHash is
+// taken before that, over srcBytes exactly as given.
+func extractBytes(filename string, srcBytes []byte, opts Options) (*ExtractResult, error) {
+	hash := fileHash(srcBytes)
+	srcBytes = bytes.TrimPrefix(srcBytes, []byte("\uFEFF"))
+	src := string(srcBytes)
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, srcBytes, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parsing file: %w", err)
+	}
+	if opts.SkipGenerated && ast.IsGenerated(file) {
+		return newResult(), nil
+	}
+
+	result := newResult()
+	result.Imports = extractImports(fset, file, filename)
+	result.Examples = extractExamples(fset, file, filename)
+	result.Files = []FileInfo{{File: filename, Package: file.Name.Name, FileHash: hash}}
+
+	scope := newFileScope(file)
+
+	consts := make(map[string]constant.Value)
+	groups := 0
+	topLevel := make(map[*ast.GenDecl]bool)
+	for _, decl := range file.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok {
+			topLevel[gd] = true
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncDecl:
+			fi := extractFunction(fset, node, filename, src, scope)
+			result.Functions = append(result.Functions, fi)
+			if opts.IncludeFuncLits {
+				result.Functions = append(result.Functions, extractFuncLits(fset, node, filename, src, scope)...)
+			}
+
+		case *ast.GenDecl:
+			if topLevel[node] && (node.Tok == token.CONST || node.Tok == token.VAR) {
+				groups++
+				result.Values = append(result.Values, extractValues(fset, node, filename, src, groups, consts)...)
+			}
+			if node.Tok == token.VAR && topLevel[node] {
+				result.Assertions = append(result.Assertions, extractAssertions(fset, node, filename)...)
+				result.Functions = append(result.Functions, extractVarFuncs(fset, node, filename, src, scope)...)
+				return true
+			}
+			if node.Tok != token.TYPE {
+				return true
+			}
+			for _, spec := range node.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				if target, ok := ts.Type.(*ast.Ident); ok && ts.Assign.IsValid() && topLevel[node] {
+					result.aliases = append(result.aliases, typeAlias{file: filename, name: ts.Name.Name, target: target.Name})
+				}
+				doc, docLines := docComment(fset, specDoc(node, ts.Doc))
+				switch t := ts.Type.(type) {
+				case *ast.StructType:
+					si := extractStruct(fset, ts, t, filename)
+					si.Doc, si.DocLines = doc, docLines
+					result.Structs = append(result.Structs, si)
+				case *ast.InterfaceType:
+					ii := extractInterface(fset, ts, t, filename)
+					ii.Doc, ii.DocLines = doc, docLines
+					result.Interfaces = append(result.Interfaces, ii)
+				}
+			}
+		}
+		return true
+	})
+
+	pkg := file.Name.Name
+	goos, goarch := fileOSArch(filename)
+	inits := 0
+	for i, fn := range result.Functions {
+		result.Functions[i].Package = pkg
+		result.Functions[i].GOOS, result.Functions[i].GOARCH = goos, goarch
+		result.Functions[i].IsMain = pkg == "main" && fn.Name == "main" && fn.Receiver == ""
+		name := fn.Name
+		if fn.IsInit {
+			// A package may declare several init functions; tell them apart
+			// by file and position within the file.
+			name = fmt.Sprintf("init#%s#%d", filepath.Base(filename), inits)
+			inits++
+		}
+		result.Functions[i].ID = symbolID("function", pkg, fn.Receiver, name)
+	}
+	for i, s := range result.Structs {
+		result.Structs[i].Package = pkg
+		result.Structs[i].GOOS, result.Structs[i].GOARCH = goos, goarch
+		result.Structs[i].ID = symbolID("struct", pkg, "", s.Name)
+	}
+	for i, iface := range result.Interfaces {
+		result.Interfaces[i].Package = pkg
+		result.Interfaces[i].GOOS, result.Interfaces[i].GOARCH = goos, goarch
+		result.Interfaces[i].ID = symbolID("interface", pkg, "", iface.Name)
+	}
+	for i := range result.Imports {
+		result.Imports[i].Package = pkg
+	}
+	for i := range result.Examples {
+		result.Examples[i].Package = pkg
+	}
+	for i := range result.Assertions {
+		result.Assertions[i].Package = pkg
+	}
+	for i := range result.aliases {
+		result.aliases[i].pkg = pkg
+	}
+	for i, v := range result.Values {
+		result.Values[i].Package = pkg
+		result.Values[i].GOOS, result.Values[i].GOARCH = goos, goarch
+		result.Values[i].ID = symbolID(v.Kind, pkg, "", v.Name)
+	}
+
+	attachMethods(result)
+
+	return result, nil
+}
+
+// extractImports returns the imports of file. Name is the explicit import
+// name (an alias, _ or .), if any. A path is taken to be in the standard
+// library when its first element has no dot, as the go command does.
+func extractImports(fset *token.FileSet, file *ast.File, filename string) []ImportInfo {
+	imports := []ImportInfo{}
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		ii := ImportInfo{
+			File: filename,
+			Line: fset.Position(imp.Pos()).Line,
+			Path: path,
+			Std:  isStdImport(path),
+		}
+		if imp.Name != nil {
+			ii.Name = imp.Name.Name
+		}
+		imports = append(imports, ii)
+	}
+	return imports
+}
+
+// isStdImport reports whether path looks like a standard library import.
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// ExtractPackageClause parses only the package clause of filename, which is
+// much cheaper than a full parse when just the file's package is wanted.
+func ExtractPackageClause(filename string) (FileInfo, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("parsing file: %w", err)
+	}
+	return FileInfo{File: filename, Package: file.Name.Name}, nil
+}
+
+// symbolID returns a stable identifier for a symbol: the first 16 hex digits
+// (64 bits) of the SHA-256 of kind, package, receiver, and name joined by NUL
+// bytes. It does not depend on file or line, so a symbol keeps its ID when it
+// moves.
+func symbolID(kind, pkg, receiver, name string) string {
+	sum := sha256.Sum256([]byte(kind + "\x00" + pkg + "\x00" + receiver + "\x00" + name))
+	return hex.EncodeToString(sum[:8])
+}
+
+// extractFunction extracts information from a function declaration.
+func extractFunction(fset *token.FileSet, fn *ast.FuncDecl, filename, src string, scope *fileScope) FunctionInfo {
+	startPos := fset.Position(fn.Pos())
+	endPos := fset.Position(fn.End())
+
+	loc := endPos.Line - startPos.Line + 1
+
+	// Extract body text from source bytes. BodyLOC counts only the lines
+	// strictly between the braces, so a body on the signature's line, like
+	// func f() { g() }, counts 0.
+	body := ""
+	bodyLOC := 0
+	if fn.Body != nil {
+		bodyLOC = max(fset.Position(fn.Body.Rbrace).Line-fset.Position(fn.Body.Lbrace).Line-1, 0)
+		bodyStart := fset.Position(fn.Body.Pos())
+		bodyEnd := fset.Position(fn.Body.End())
+		if bodyStart.Offset >= 0 && bodyEnd.Offset <= len(src) {
+			body = src[bodyStart.Offset:bodyEnd.Offset]
+		}
+	}
+
+	// Extract parameter names and types.
+	params := extractParams(fn.Type.Params)
+	paramTypes := extractParamTypes(fn.Type.Params)
+	results := extractParamTypes(fn.Type.Results)
+	takesContext, contextIsFirst := contextParams(paramTypes)
+	isVariadic := false
+	if list := fn.Type.Params.List; len(list) > 0 {
+		_, isVariadic = list[len(list)-1].Type.(*ast.Ellipsis)
+	}
+
+	// Extract receiver type name, both bare (List) for grouping methods and
+	// with its type parameters (List[T]) for precise rendering, plus the
+	// receiver variable name, which is empty for func (*List) M().
+	receiver := ""
+	receiverFull := ""
+	receiverName := ""
+	pointerReceiver := false
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		if names := fn.Recv.List[0].Names; len(names) > 0 {
+			receiverName = names[0].Name
+		}
+		recvType := fn.Recv.List[0].Type
+		receiver = receiverTypeName(recvType)
+		if star, ok := recvType.(*ast.StarExpr); ok {
+			pointerReceiver = true
+			recvType = star.X
+		}
+		receiverFull = typeString(recvType)
+	}
+
+	externalCalls, internalCalls := countCalls(fn.Body, scope)
+	hasGoto, labeledStmts := countLabels(fn.Body)
+	switchCases, selectCases := countCases(fn.Body)
+	locksAcquired, locksReleased := lockCalls(fn.Body)
+
+	doc, docLines := docComment(fset, fn.Doc)
+
+	name := fn.Name.Name
+	exported := isExported(name)
+	isInit := name == "init" && fn.Recv == nil && len(paramTypes) == 0 && len(results) == 0
+
+	return FunctionInfo{
+		Name:             name,
+		File:             filename,
+		Line:             startPos.Line,
+		Column:           startPos.Column,
+		Offset:           startPos.Offset,
+		EndLine:          endPos.Line,
+		LOC:              loc,
+		BodyLOC:          bodyLOC,
+		Body:             body,
+		Doc:              doc,
+		DocLines:         docLines,
+		TypeParams:       extractTypeParams(fn.Type.TypeParams),
+		Params:           params,
+		ParamTypes:       paramTypes,
+		Results:          results,
+		IsVariadic:       isVariadic,
+		IsInit:           isInit,
+		Complexity:       cyclomatic(fn.Body),
+		SwitchCases:      switchCases,
+		SelectCases:      selectCases,
+		AssignCount:      countAssignments(fn.Body),
+		MagicNumbers:     countMagicNumbers(fn.Body),
+		CommentDensity:   commentDensity(fset, fn, scope),
+		Halstead:         halstead(fn.Body),
+		NormalizedHash:   normalizedBodyHash(fn.Body),
+		HasGoto:          hasGoto,
+		LabeledStmts:     labeledStmts,
+		NeverReturns:     neverReturns(fn.Body, scope),
+		ConstantReturn:   constantReturn(fset, fn, src, scope),
+		ReferencedTypes:  referencedTypes(fn, scope),
+		ShadowsBuiltin:   shadowedBuiltins(fn),
+		IsAccessor:       isAccessor(fn),
+		IsEmpty:          isPlaceholderBody(fn.Body),
+		HasNoBody:        fn.Body == nil,
+		UsesReflection:   usesReflection(fn.Body, scope),
+		Receiver:         receiver,
+		ReceiverFull:     receiverFull,
+		ReceiverName:     receiverName,
+		PointerReceiver:  pointerReceiver,
+		Exported:         exported,
+		ExternalCalls:    externalCalls,
+		Calls:            callTargets(fn.Body, scope),
+		CalledMethods:    calledMethods(fn.Body, scope),
+		LargeValueParams: []string{},
+		LocksAcquired:    locksAcquired,
+		LocksReleased:    locksReleased,
+		selectors:        fieldSelectors(fn.Body, scope),
+		identifiers:      bodyIdentifiers(fn.Body),
+		discards:         discardedCalls(fn.Body, scope),
+		InternalCalls:    internalCalls,
+		TakesContext:     takesContext,
+		ContextIsFirst:   contextIsFirst,
+	}
+}
+
+// extractVarFuncs extracts a FunctionInfo for each package-level variable
+// initialized with a function literal (var handler = func(...) {...}),
+// named after the variable.
+func extractVarFuncs(fset *token.FileSet, decl *ast.GenDecl, filename, src string, scope *fileScope) []FunctionInfo {
+	var funcs []FunctionInfo
+	for _, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		for i, name := range vs.Names {
+			if i >= len(vs.Values) || name.Name == "_" {
+				continue
+			}
+			lit, ok := vs.Values[i].(*ast.FuncLit)
+			if !ok {
+				continue
+			}
+			fn := &ast.FuncDecl{Doc: specDoc(decl, vs.Doc), Name: name, Type: lit.Type, Body: lit.Body}
+			funcs = append(funcs, extractFunction(fset, fn, filename, src, scope))
+		}
+	}
+	return funcs
+}
+
+// extractTypeParams renders each type parameter with its constraint, one
+// entry per name: [K comparable, V int | string] gives "K comparable" and
+// "V int | string".
+func extractTypeParams(fields *ast.FieldList) []string {
+	params := []string{}
+	for _, field := range fieldsOf(fields) {
+		constraint := typeString(field.Type)
+		for _, name := range field.Names {
+			params = append(params, name.Name+" "+constraint)
+		}
+	}
+	return params
+}
+
+// specDoc returns the doc comment of a spec within decl: its own, or the
+// declaration's when decl is unparenthesized and so holds just that spec.
+func specDoc(decl *ast.GenDecl, doc *ast.CommentGroup) *ast.CommentGroup {
+	if doc == nil && !decl.Lparen.IsValid() {
+		return decl.Doc
+	}
+	return doc
+}
+
+// docComment returns the text of a doc comment and the number of source
+// lines it spans; both are zero for a nil group.
+func docComment(fset *token.FileSet, group *ast.CommentGroup) (string, int) {
+	if group == nil {
+		return "", 0
+	}
+	lines := fset.Position(group.End()).Line - fset.Position(group.Pos()).Line + 1
+	return group.Text(), lines
+}
+
+// extractParams extracts parameter names from a field list.
+func extractParams(fields *ast.FieldList) []string {
+	if fields == nil {
+		return []string{}
+	}
+	var params []string
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			params = append(params, name.Name)
+		}
+		// If a field has no names (bare type like in interface method signatures),
+		// we skip it as specified.
+	}
+	if params == nil {
+		return []string{}
+	}
+	return params
+}
+
+// extractParamTypes extracts one type string per parameter position, so
+// "a, b int" yields two entries and unnamed parameters yield one each.
+func extractParamTypes(fields *ast.FieldList) []string {
+	types := []string{}
+	if fields == nil {
+		return types
+	}
+	for _, field := range fields.List {
+		t := typeString(field.Type)
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// contextParams reports whether any parameter is a context.Context and
+// whether the first one is.
+func contextParams(paramTypes []string) (takesContext, first bool) {
+	for i, t := range paramTypes {
+		if t == "context.Context" {
+			return true, i == 0
+		}
+	}
+	return false, false
+}
+
+// receiverTypeName extracts the type name from a receiver expression,
+// handling both value and pointer receivers.
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		// Generic type: T[P]
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		// Generic type with several parameters: T[K, V]
+		return receiverTypeName(t.X)
+	default:
+		return ""
+	}
+}
+
+// extractStruct extracts information from a struct type declaration.
+func extractStruct(fset *token.FileSet, ts *ast.TypeSpec, st *ast.StructType, filename string) StructInfo {
+	startPos := fset.Position(ts.Pos())
+	endPos := fset.Position(st.End())
+	loc := endPos.Line - startPos.Line + 1
+
+	var fields []string
+	var embedded []string
+	zeroValues := []string{}
+
+	if st.Fields != nil {
+		for _, field := range st.Fields.List {
+			if len(field.Names) == 0 {
+				// Embedded type.
+				embedded = append(embedded, typeString(field.Type))
+			} else {
+				zero := zeroValue(field.Type)
+				for _, name := range field.Names {
+					fields = append(fields, name.Name)
+					zeroValues = append(zeroValues, zero)
+				}
+			}
+		}
+	}
+
+	if fields == nil {
+		fields = []string{}
+	}
+	if embedded == nil {
+		embedded = []string{}
+	}
+
+	layouts := fieldLayouts(st.Fields)
+	size, _ := structLayout(layouts)
+
+	name := ts.Name.Name
+	return StructInfo{
+		Name:                name,
+		File:                filename,
+		Line:                startPos.Line,
+		Column:              startPos.Column,
+		Offset:              startPos.Offset,
+		LOC:                 loc,
+		TypeParams:          extractTypeParams(ts.TypeParams),
+		Methods:             []string{},
+		Fields:              fields,
+		FieldZeroValues:     zeroValues,
+		FieldUsage:          make([]int, len(fields)),
+		JSONFields:          jsonFields(st.Fields),
+		Embedded:            embedded,
+		Exported:            isExported(name),
+		NumFields:           len(fields),
+		NumEmbedded:         len(embedded),
+		SizeBytes:           size,
+		PaddingBytes:        paddingBytes(layouts, size),
+		SuggestedFieldOrder: suggestFieldOrder(layouts, size),
+	}
+}
+
+// extractInterface extracts information from an interface type declaration.
+func extractInterface(fset *token.FileSet, ts *ast.TypeSpec, it *ast.InterfaceType, filename string) InterfaceInfo {
+	startPos := fset.Position(ts.Pos())
+
+	methods, signatures, details, embedded := []string{}, []string{}, []MethodInfo{}, []string{}
+	if it.Methods != nil {
+		for _, method := range it.Methods.List {
+			ft, ok := method.Type.(*ast.FuncType)
+			if !ok {
+				// An embedded interface or a type set term such as ~int | ~string.
+				embedded = append(embedded, typeString(method.Type))
+				continue
+			}
+			for _, name := range method.Names {
+				methods = append(methods, name.Name)
+				signatures = append(signatures, name.Name+funcSignatureString(ft))
+				details = append(details, methodInfo(name.Name, ft))
+			}
+		}
+	}
+
+	return InterfaceInfo{
+		Name:          ts.Name.Name,
+		File:          filename,
+		Line:          startPos.Line,
+		Column:        startPos.Column,
+		Offset:        startPos.Offset,
+		TypeParams:    extractTypeParams(ts.TypeParams),
+		Methods:       methods,
+		Signatures:    signatures,
+		MethodDetails: details,
+		Embedded:      embedded,
+		Implementers:  []string{},
+	}
+}
+
+// zeroValue returns the Go source of the zero value of a type expression,
+// e.g. 0, "", false, nil, or [4]byte{}. Without type checking the underlying
+// type of a named type is unknown, so named types other than the predeclared
+// ones, including those from other packages and type parameters, yield "":
+// time.Duration's zero value is 0 and a named slice's is nil, neither of
+// which a T{} literal would be.
+func zeroValue(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return `""`
+		case "bool":
+			return "false"
+		case "error", "any":
+			return "nil"
+		}
+		if _, ok := basicLayouts[t.Name]; ok {
+			return "0"
+		}
+	case *ast.ParenExpr:
+		return zeroValue(t.X)
+	case *ast.StarExpr, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType:
+		return "nil"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "nil"
+		}
+		return typeString(expr) + "{}"
+	case *ast.StructType:
+		return typeString(expr) + "{}"
+	}
+	return ""
+}
+
+// typeString returns a string representation of an AST type expression.
+func typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + typeString(t.X)
+	case *ast.SelectorExpr:
+		return typeString(t.X) + "." + t.Sel.Name
+	case *ast.ArrayType:
+		if t.Len != nil {
+			// Fixed-size array; the length may be a literal, a constant,
+			// an expression, or ... in composite literals.
+			return "[" + types.ExprString(t.Len) + "]" + typeString(t.Elt)
+		}
+		return "[]" + typeString(t.Elt)
+	case *ast.MapType:
+		return "map[" + typeString(t.Key) + "]" + typeString(t.Value)
+	case *ast.StructType:
+		return "struct{" + structFieldsString(t.Fields) + "}"
+	case *ast.InterfaceType:
+		return "interface{" + interfaceMethodsString(t.Methods) + "}"
+	case *ast.Ellipsis:
+		return "..." + typeString(t.Elt)
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return "chan<- " + typeString(t.Value)
+		case ast.RECV:
+			return "<-chan " + typeString(t.Value)
+		default:
+			return "chan " + typeString(t.Value)
+		}
+	case *ast.FuncType:
+		return "func" + funcSignatureString(t)
+	case *ast.IndexExpr:
+		return typeString(t.X) + "[" + typeString(t.Index) + "]"
+	case *ast.IndexListExpr:
+		indices := make([]string, len(t.Indices))
+		for i, index := range t.Indices {
+			indices[i] = typeString(index)
+		}
+		return typeString(t.X) + "[" + strings.Join(indices, ", ") + "]"
+	case *ast.BinaryExpr:
+		// Union in a constraint: int | string.
+		if t.Op == token.OR {
+			return typeString(t.X) + " | " + typeString(t.Y)
+		}
+		return fmt.Sprintf("%T", expr)
+	case *ast.UnaryExpr:
+		// Underlying-type term in a constraint: ~int.
+		if t.Op == token.TILDE {
+			return "~" + typeString(t.X)
+		}
+		return fmt.Sprintf("%T", expr)
+	case *ast.ParenExpr:
+		return "(" + typeString(t.X) + ")"
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+// structFieldsString renders the fields of an anonymous struct type, e.g.
+// "A, B int; C string `json:"c"`".
+func structFieldsString(fields *ast.FieldList) string {
+	if fields == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(fields.List))
+	for _, field := range fields.List {
+		var names []string
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		part := typeString(field.Type)
+		if len(names) > 0 {
+			part = strings.Join(names, ", ") + " " + part
+		}
+		if field.Tag != nil {
+			part += " " + field.Tag.Value
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// interfaceMethodsString renders the methods and embedded types of an
+// anonymous interface type, e.g. "io.Reader; Close() error".
+func interfaceMethodsString(methods *ast.FieldList) string {
+	if methods == nil {
+		return ""
+	}
+	parts := make([]string, 0, len(methods.List))
+	for _, method := range methods.List {
+		ft, ok := method.Type.(*ast.FuncType)
+		if !ok || len(method.Names) == 0 {
+			parts = append(parts, typeString(method.Type))
+			continue
+		}
+		parts = append(parts, method.Names[0].Name+funcSignatureString(ft))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// funcSignatureString renders the parameter and result types of a function
+// type, e.g. "(string, ...int) (bool, error)".
+func funcSignatureString(ft *ast.FuncType) string {
+	sig := "(" + strings.Join(extractParamTypes(ft.Params), ", ") + ")"
+	results := extractParamTypes(ft.Results)
+	switch len(results) {
+	case 0:
+	case 1:
+		sig += " " + results[0]
+	default:
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
+
+// methodInfo describes a method with the given name and type. Unnamed
+// parameters are given positional names arg0, arg1, ... so that the
+// signature can be written out as a method with a body, as mock generators
+// do.
+func methodInfo(name string, ft *ast.FuncType) MethodInfo {
+	names, types := extractParams(ft.Params), extractParamTypes(ft.Params)
+	params := []Param{}
+	for i, typ := range types {
+		param := Param{Name: fmt.Sprintf("arg%d", i), Type: typ}
+		// Parameters are either all named or all unnamed.
+		if len(names) == len(types) {
+			param.Name = names[i]
+		}
+		params = append(params, param)
+	}
+	return MethodInfo{Name: name, Params: params, Returns: extractParamTypes(ft.Results)}
+}
+
+// isExported checks whether a name is exported (starts with an uppercase letter).
+func isExported(name string) bool {
+	if name == "" {
+		return false
+	}
+	r := []rune(name)
+	return unicode.IsUpper(r[0])
+}
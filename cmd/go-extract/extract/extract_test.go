@@ -0,0 +1,1165 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractFunctions(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "main.go")
+	os.WriteFile(src, []byte(`package main
+
+func Hello(name string) string {
+	return "Hello, " + name
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	return nil
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 2 {
+		t.Fatalf("expected 2 functions, got %d", len(result.Functions))
+	}
+	if result.Functions[0].Name != "Hello" {
+		t.Errorf("expected Hello, got %s", result.Functions[0].Name)
+	}
+	if result.Functions[0].Exported != true {
+		t.Errorf("expected Hello to be exported")
+	}
+	if len(result.Functions[0].Params) != 1 {
+		t.Errorf("expected 1 param for Hello, got %d", len(result.Functions[0].Params))
+	}
+	if len(result.Functions[0].Params) > 0 && result.Functions[0].Params[0] != "name" {
+		t.Errorf("expected param 'name', got %s", result.Functions[0].Params[0])
+	}
+	if result.Functions[0].LOC < 3 {
+		t.Errorf("expected LOC >= 3 for Hello, got %d", result.Functions[0].LOC)
+	}
+	if result.Functions[0].Body == "" {
+		t.Errorf("expected non-empty body for Hello")
+	}
+	if result.Functions[1].Name != "Start" {
+		t.Errorf("expected Start, got %s", result.Functions[1].Name)
+	}
+	if result.Functions[1].Receiver != "Server" {
+		t.Errorf("expected receiver Server, got %s", result.Functions[1].Receiver)
+	}
+}
+
+func TestExtractStructs(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "types.go")
+	os.WriteFile(src, []byte(`package main
+
+type User struct {
+	Name  string
+	Email string
+}
+
+type Admin struct {
+	User
+	Role string
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Structs) != 2 {
+		t.Fatalf("expected 2 structs, got %d", len(result.Structs))
+	}
+	if result.Structs[0].Name != "User" {
+		t.Errorf("expected User, got %s", result.Structs[0].Name)
+	}
+	if len(result.Structs[0].Fields) != 2 {
+		t.Errorf("expected 2 fields, got %d", len(result.Structs[0].Fields))
+	}
+	if result.Structs[0].Exported != true {
+		t.Errorf("expected User to be exported")
+	}
+	if result.Structs[1].Name != "Admin" {
+		t.Errorf("expected Admin, got %s", result.Structs[1].Name)
+	}
+	if len(result.Structs[1].Embedded) != 1 {
+		t.Errorf("expected 1 embedded type for Admin, got %d", len(result.Structs[1].Embedded))
+	}
+	if len(result.Structs[1].Embedded) > 0 && result.Structs[1].Embedded[0] != "User" {
+		t.Errorf("expected embedded type 'User', got %s", result.Structs[1].Embedded[0])
+	}
+	if len(result.Structs[1].Fields) != 1 {
+		t.Errorf("expected 1 field for Admin, got %d", len(result.Structs[1].Fields))
+	}
+	if result.Structs[1].NumFields != 1 || result.Structs[1].NumEmbedded != 1 {
+		t.Errorf("expected Admin to count 1 field and 1 embedded, got %d and %d",
+			result.Structs[1].NumFields, result.Structs[1].NumEmbedded)
+	}
+}
+
+func TestExtractFieldZeroValues(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "config.go")
+	os.WriteFile(src, []byte(`package main
+
+type Config struct {
+	Name, Host string
+	Port       int
+	Ratio      float64
+	Debug      bool
+	Err        error
+	Next       *Config
+	Tags       []string
+	Labels     map[string]string
+	Done       chan struct{}
+	Hook       func()
+	Sum        [4]byte
+	Server
+	Limits     Limits
+	Timeout    time.Duration
+	IDs        IDs
+	Point      struct{ X int }
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := []string{`""`, `""`, "0", "0", "false", "nil", "nil", "nil", "nil", "nil", "nil", "[4]byte{}", "", "", "", "struct{X int}{}"}
+	got := result.Structs[0].FieldZeroValues
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestExtractInterfaces(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "iface.go")
+	os.WriteFile(src, []byte(`package main
+
+type Reader interface {
+	Read(p []byte) (n int, err error)
+	Close() error
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Interfaces) != 1 {
+		t.Fatalf("expected 1 interface, got %d", len(result.Interfaces))
+	}
+	if result.Interfaces[0].Name != "Reader" {
+		t.Errorf("expected Reader, got %s", result.Interfaces[0].Name)
+	}
+	if len(result.Interfaces[0].Methods) != 2 {
+		t.Errorf("expected 2 methods, got %d", len(result.Interfaces[0].Methods))
+	}
+	if len(result.Interfaces[0].Methods) > 0 && result.Interfaces[0].Methods[0] != "Read" {
+		t.Errorf("expected method 'Read', got %s", result.Interfaces[0].Methods[0])
+	}
+	if len(result.Interfaces[0].Methods) > 1 && result.Interfaces[0].Methods[1] != "Close" {
+		t.Errorf("expected method 'Close', got %s", result.Interfaces[0].Methods[1])
+	}
+}
+
+func TestExtractInterfaceMethodDetails(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "store.go")
+	os.WriteFile(src, []byte(`package store
+
+type Store interface {
+	io.Closer
+	fmt.Stringer
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(string, []byte, ...Option)
+	Reset()
+}
+
+type Number interface {
+	~int | ~float64
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	store := result.Interfaces[0]
+	if strings.Join(store.Embedded, ",") != "io.Closer,fmt.Stringer" {
+		t.Errorf("expected embedded [io.Closer fmt.Stringer], got %v", store.Embedded)
+	}
+	expected := []MethodInfo{
+		{Name: "Get", Params: []Param{{"ctx", "context.Context"}, {"key", "string"}}, Returns: []string{"[]byte", "error"}},
+		{Name: "Put", Params: []Param{{"arg0", "string"}, {"arg1", "[]byte"}, {"arg2", "...Option"}}, Returns: []string{}},
+		{Name: "Reset", Params: []Param{}, Returns: []string{}},
+	}
+	if !reflect.DeepEqual(store.MethodDetails, expected) {
+		t.Errorf("expected method details %+v, got %+v", expected, store.MethodDetails)
+	}
+
+	number := result.Interfaces[1]
+	if len(number.MethodDetails) != 0 || strings.Join(number.Embedded, ",") != "~int | ~float64" {
+		t.Errorf("expected no methods and embedded [~int | ~float64], got %+v and %v", number.MethodDetails, number.Embedded)
+	}
+}
+
+func TestExtractMethodsAttachedToStruct(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "server.go")
+	os.WriteFile(src, []byte(`package main
+
+type Server struct {
+	Host string
+	Port int
+}
+
+func (s *Server) Start() error {
+	return nil
+}
+
+func (s Server) Addr() string {
+	return s.Host
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Structs) != 1 {
+		t.Fatalf("expected 1 struct, got %d", len(result.Structs))
+	}
+	if len(result.Structs[0].Methods) != 2 {
+		t.Errorf("expected 2 methods on Server, got %d", len(result.Structs[0].Methods))
+	}
+	if result.Structs[0].NumMethods != 2 {
+		t.Errorf("expected NumMethods 2, got %d", result.Structs[0].NumMethods)
+	}
+	if result.Structs[0].NumFields != 2 {
+		t.Errorf("expected NumFields 2, got %d", result.Structs[0].NumFields)
+	}
+}
+
+func TestExtractUnexportedNames(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "private.go")
+	os.WriteFile(src, []byte(`package main
+
+func helper() {}
+
+type config struct {
+	value string
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 1 {
+		t.Fatalf("expected 1 function, got %d", len(result.Functions))
+	}
+	if result.Functions[0].Exported {
+		t.Errorf("expected helper to be unexported")
+	}
+	if len(result.Structs) != 1 {
+		t.Fatalf("expected 1 struct, got %d", len(result.Structs))
+	}
+	if result.Structs[0].Exported {
+		t.Errorf("expected config to be unexported")
+	}
+}
+
+func TestExtractEmptyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "empty.go")
+	os.WriteFile(src, []byte(`package main
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 0 {
+		t.Errorf("expected 0 functions, got %d", len(result.Functions))
+	}
+	if len(result.Structs) != 0 {
+		t.Errorf("expected 0 structs, got %d", len(result.Structs))
+	}
+	if len(result.Interfaces) != 0 {
+		t.Errorf("expected 0 interfaces, got %d", len(result.Interfaces))
+	}
+}
+
+func TestExtractFileNotFound(t *testing.T) {
+	_, err := extractFile("/nonexistent/file.go", Options{})
+	if err == nil {
+		t.Fatalf("expected error for nonexistent file")
+	}
+}
+
+func TestExtractMultipleParamsAndReturns(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "multi.go")
+	os.WriteFile(src, []byte(`package main
+
+func Process(a int, b string, c bool) (string, error) {
+	return "", nil
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 1 {
+		t.Fatalf("expected 1 function, got %d", len(result.Functions))
+	}
+	if len(result.Functions[0].Params) != 3 {
+		t.Errorf("expected 3 params, got %d", len(result.Functions[0].Params))
+	}
+	expectedParams := []string{"a", "b", "c"}
+	for i, expected := range expectedParams {
+		if i < len(result.Functions[0].Params) && result.Functions[0].Params[i] != expected {
+			t.Errorf("expected param %d to be %s, got %s", i, expected, result.Functions[0].Params[i])
+		}
+	}
+}
+
+func TestExtractLineNumbers(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "lines.go")
+	os.WriteFile(src, []byte(`package main
+
+// Comment
+func First() {
+}
+
+func Second() {
+	x := 1
+	_ = x
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 2 {
+		t.Fatalf("expected 2 functions, got %d", len(result.Functions))
+	}
+	// First starts at line 4
+	if result.Functions[0].Line != 4 {
+		t.Errorf("expected First at line 4, got %d", result.Functions[0].Line)
+	}
+	// Second starts at line 7
+	if result.Functions[1].Line != 7 {
+		t.Errorf("expected Second at line 7, got %d", result.Functions[1].Line)
+	}
+	if result.Functions[1].EndLine != 10 {
+		t.Errorf("expected Second end at line 10, got %d", result.Functions[1].EndLine)
+	}
+}
+
+func TestExtractPointerReceivers(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "recv.go")
+	os.WriteFile(src, []byte(`package main
+
+type Cache struct{}
+
+func (c *Cache) Get() {}
+func (c *Cache) Put() {}
+
+type Point struct{}
+
+func (p *Point) Move() {}
+func (p Point) String() string { return "" }
+
+type Empty struct{}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if !result.Functions[0].PointerReceiver {
+		t.Errorf("expected Get to have a pointer receiver")
+	}
+	if result.Functions[3].PointerReceiver {
+		t.Errorf("expected String to have a value receiver")
+	}
+	if len(result.Structs) != 3 {
+		t.Fatalf("expected 3 structs, got %d", len(result.Structs))
+	}
+	if !result.Structs[0].AllPointerMethods {
+		t.Errorf("expected Cache to have all pointer methods")
+	}
+	if result.Structs[1].AllPointerMethods {
+		t.Errorf("expected Point with mixed receivers to report false")
+	}
+	if result.Structs[2].AllPointerMethods {
+		t.Errorf("expected Empty with no methods to report false")
+	}
+}
+
+func TestExtractPackageName(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "server.go")
+	os.WriteFile(src, []byte(`package server
+
+type Server struct{}
+
+type Handler interface {
+	Serve()
+}
+
+func New() *Server { return &Server{} }
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if result.Functions[0].Package != "server" {
+		t.Errorf("expected function package server, got %q", result.Functions[0].Package)
+	}
+	if result.Structs[0].Package != "server" {
+		t.Errorf("expected struct package server, got %q", result.Structs[0].Package)
+	}
+	if result.Interfaces[0].Package != "server" {
+		t.Errorf("expected interface package server, got %q", result.Interfaces[0].Package)
+	}
+}
+
+func TestExtractContextParams(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "ctx.go")
+	os.WriteFile(src, []byte(`package main
+
+import "context"
+
+func First(ctx context.Context, id string) {}
+
+func (s *Server) Second(id string, ctx context.Context) {}
+
+func None(id string, opts ...func(int) error) {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 3 {
+		t.Fatalf("expected 3 functions, got %d", len(result.Functions))
+	}
+	first, second, none := result.Functions[0], result.Functions[1], result.Functions[2]
+	if !first.TakesContext || !first.ContextIsFirst {
+		t.Errorf("expected First to take context as first param")
+	}
+	if !second.TakesContext || second.ContextIsFirst {
+		t.Errorf("expected Second to take context, but not first")
+	}
+	if none.TakesContext || none.ContextIsFirst {
+		t.Errorf("expected None not to take context")
+	}
+	if len(none.ParamTypes) != 2 || none.ParamTypes[1] != "...func(int) error" {
+		t.Errorf("expected variadic func param type, got %v", none.ParamTypes)
+	}
+}
+
+func TestTypeStringAnonymousTypes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "anon.go")
+	os.WriteFile(src, []byte(`package main
+
+func Configure(opts struct {
+	Host, Addr string
+	Port int `+"`json:\"port\"`"+`
+}, r interface {
+	io.Reader
+	Close() error
+}, any interface{}, empty struct{}) {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := []string{
+		"struct{Host, Addr string; Port int `json:\"port\"`}",
+		"interface{io.Reader; Close() error}",
+		"interface{}",
+		"struct{}",
+	}
+	types := result.Functions[0].ParamTypes
+	if len(types) != len(expected) {
+		t.Fatalf("expected %d param types, got %v", len(expected), types)
+	}
+	for i, want := range expected {
+		if types[i] != want {
+			t.Errorf("param %d: expected %s, got %s", i, want, types[i])
+		}
+	}
+}
+
+func TestExtractStableSymbolIDs(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.go")
+	os.WriteFile(first, []byte(`package main
+
+type Server struct{}
+
+func (s *Server) Start() {}
+
+func init() {}
+func init() {}
+`), 0644)
+	moved := filepath.Join(dir, "moved.go")
+	os.WriteFile(moved, []byte(`package main
+
+// Lines shift, but IDs should not.
+
+func (s *Server) Start() {
+	println("started")
+}
+`), 0644)
+
+	a, err := extractFile(first, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	b, err := extractFile(moved, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if a.Functions[0].ID == "" || len(a.Functions[0].ID) != 16 {
+		t.Errorf("expected 16-character ID, got %q", a.Functions[0].ID)
+	}
+	if a.Functions[0].ID != b.Functions[0].ID {
+		t.Errorf("expected moved method to keep its ID")
+	}
+	if a.Functions[1].ID == a.Functions[2].ID {
+		t.Errorf("expected distinct IDs for separate init functions")
+	}
+	if a.Structs[0].ID == symbolID("function", "main", "", "Server") {
+		t.Errorf("expected kind to be part of the ID")
+	}
+}
+
+func TestExtractPackageLevelFuncVars(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "vars.go")
+	os.WriteFile(src, []byte(`package main
+
+var handler = func(w Writer, r *Request) {
+	w.Write(nil)
+}
+
+var (
+	name   = "svc"
+	helper = func() int { return 1 }
+)
+
+func Run() {
+	local := func() {}
+	local()
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 3 {
+		t.Fatalf("expected 3 functions, got %d", len(result.Functions))
+	}
+	handler := result.Functions[0]
+	if handler.Name != "handler" {
+		t.Errorf("expected handler, got %s", handler.Name)
+	}
+	if len(handler.Params) != 2 || handler.Params[1] != "r" {
+		t.Errorf("expected params [w r], got %v", handler.Params)
+	}
+	if handler.Line != 3 || handler.LOC != 3 {
+		t.Errorf("expected handler at line 3 with LOC 3, got line %d LOC %d", handler.Line, handler.LOC)
+	}
+	if handler.Body == "" {
+		t.Errorf("expected non-empty body for handler")
+	}
+	if result.Functions[1].Name != "helper" {
+		t.Errorf("expected helper, got %s", result.Functions[1].Name)
+	}
+	if result.Functions[2].Name != "Run" {
+		t.Errorf("expected local function literals to be skipped, got %s", result.Functions[2].Name)
+	}
+}
+
+func TestExtractGenericReceiverFull(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "list.go")
+	os.WriteFile(src, []byte(`package main
+
+type List[T any] struct {
+	items []T
+}
+
+func (l *List[T]) Add(item T) {}
+
+func (s Server) Name() string { return "" }
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	add := result.Functions[0]
+	if add.Receiver != "List" {
+		t.Errorf("expected bare receiver List, got %s", add.Receiver)
+	}
+	if add.ReceiverFull != "List[T]" {
+		t.Errorf("expected full receiver List[T], got %s", add.ReceiverFull)
+	}
+	if result.Functions[1].ReceiverFull != "Server" {
+		t.Errorf("expected full receiver Server, got %s", result.Functions[1].ReceiverFull)
+	}
+	if len(result.Structs[0].Methods) != 1 {
+		t.Errorf("expected Add attached to List, got %v", result.Structs[0].Methods)
+	}
+}
+
+func TestExtractVariadic(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "variadic.go")
+	os.WriteFile(src, []byte(`package main
+
+func Printf(format string, args ...any) {}
+
+func Sum(nums []int) int { return 0 }
+
+func Nothing() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if !result.Functions[0].IsVariadic {
+		t.Errorf("expected Printf to be variadic")
+	}
+	if result.Functions[1].IsVariadic {
+		t.Errorf("expected Sum not to be variadic")
+	}
+	if result.Functions[2].IsVariadic {
+		t.Errorf("expected zero-param Nothing not to be variadic")
+	}
+}
+
+func TestExtractEntryPoints(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "main.go")
+	os.WriteFile(src, []byte(`package main
+
+func init() {}
+
+func init() {}
+
+func main() {}
+
+func (s *Server) init() {}
+`), 0644)
+	lib := filepath.Join(dir, "lib.go")
+	os.WriteFile(lib, []byte(`package lib
+
+func main() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if !result.Functions[0].IsInit || !result.Functions[1].IsInit {
+		t.Errorf("expected both init functions to be flagged")
+	}
+	if !result.Functions[2].IsMain || result.Functions[2].IsInit {
+		t.Errorf("expected main to be flagged as main only")
+	}
+	if result.Functions[3].IsInit {
+		t.Errorf("expected init method not to be flagged")
+	}
+
+	libResult, err := extractFile(lib, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if libResult.Functions[0].IsMain {
+		t.Errorf("expected main outside package main not to be flagged")
+	}
+}
+
+func TestExtractQualifiedAndGenericEmbeds(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "embeds.go")
+	os.WriteFile(src, []byte(`package main
+
+type Stream struct {
+	io.Reader
+	*sync.Mutex
+	cache.Map[string, int]
+	Created time.Time
+}
+
+func (p *Pair[K, V]) Swap() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := []string{"io.Reader", "*sync.Mutex", "cache.Map[string, int]"}
+	embedded := result.Structs[0].Embedded
+	if len(embedded) != len(expected) {
+		t.Fatalf("expected %d embedded types, got %v", len(expected), embedded)
+	}
+	for i, want := range expected {
+		if embedded[i] != want {
+			t.Errorf("embedded %d: expected %s, got %s", i, want, embedded[i])
+		}
+	}
+	swap := result.Functions[0]
+	if swap.Receiver != "Pair" || swap.ReceiverFull != "Pair[K, V]" {
+		t.Errorf("expected receiver Pair / Pair[K, V], got %s / %s", swap.Receiver, swap.ReceiverFull)
+	}
+}
+
+func TestExtractMultiParamGenericMethod(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "map.go")
+	os.WriteFile(src, []byte(`package main
+
+type Map[K comparable, V any] struct {
+	items map[K]V
+}
+
+func (m *Map[K, V]) Get(key K) (V, bool) {
+	v, ok := m.items[key]
+	return v, ok
+}
+
+func Merge(a, b Map[string, int]) Map[string, int] { return a }
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	get := result.Functions[0]
+	if get.Receiver != "Map" {
+		t.Errorf("expected receiver Map, got %q", get.Receiver)
+	}
+	if len(result.Structs[0].Methods) != 1 || result.Structs[0].Methods[0] != "Get" {
+		t.Errorf("expected Get attached to Map, got %v", result.Structs[0].Methods)
+	}
+	merge := result.Functions[1]
+	if len(merge.ParamTypes) != 2 || merge.ParamTypes[0] != "Map[string, int]" {
+		t.Errorf("expected Map[string, int] param types, got %v", merge.ParamTypes)
+	}
+	if len(merge.Results) != 1 || merge.Results[0] != "Map[string, int]" {
+		t.Errorf("expected Map[string, int] result, got %v", merge.Results)
+	}
+}
+
+func TestExtractBlankReceiverNames(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "blank.go")
+	os.WriteFile(src, []byte(`package main
+
+type Server struct{}
+
+func (*Server) Ping() {}
+
+func (_ Server) Name() string { return "server" }
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 2 {
+		t.Fatalf("expected 2 methods, got %d", len(result.Functions))
+	}
+	ping, name := result.Functions[0], result.Functions[1]
+	if ping.Receiver != "Server" || !ping.PointerReceiver {
+		t.Errorf("expected Ping on *Server, got %s (pointer=%v)", ping.Receiver, ping.PointerReceiver)
+	}
+	if name.Receiver != "Server" || name.PointerReceiver {
+		t.Errorf("expected Name on Server, got %s (pointer=%v)", name.Receiver, name.PointerReceiver)
+	}
+	if len(result.Structs[0].Methods) != 2 {
+		t.Errorf("expected both methods attached to Server, got %v", result.Structs[0].Methods)
+	}
+}
+
+func TestExtractReceiverName(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "recv.go")
+	os.WriteFile(src, []byte(`package main
+
+func (s *Server) Start() {}
+
+func (*Server) Ping() {}
+
+func (_ Server) Name() string { return "server" }
+
+func Run() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	for i, want := range []string{"s", "", "_", ""} {
+		if got := result.Functions[i].ReceiverName; got != want {
+			t.Errorf("%s: expected receiver name %q, got %q", result.Functions[i].Name, want, got)
+		}
+	}
+}
+
+func TestExtractBodyLOC(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "body.go")
+	os.WriteFile(src, []byte(`package main
+
+func Short() { run() }
+
+func Single(x int) int {
+	x++
+	return x
+}
+
+func Multi(
+	a int,
+	b int,
+) (
+	int, error,
+) {
+	return a + b, nil
+}
+
+func Empty() {
+}
+
+func asm()
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := map[string][2]int{
+		"Short":  {1, 0},
+		"Single": {4, 2},
+		"Multi":  {8, 1},
+		"Empty":  {2, 0},
+		"asm":    {1, 0},
+	}
+	for _, fn := range result.Functions {
+		want := expected[fn.Name]
+		if fn.LOC != want[0] || fn.BodyLOC != want[1] {
+			t.Errorf("%s: expected loc %d and body_loc %d, got %d and %d", fn.Name, want[0], want[1], fn.LOC, fn.BodyLOC)
+		}
+	}
+}
+
+func TestExtractFileHash(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	os.WriteFile(a, []byte("package main\n"), 0644)
+	os.WriteFile(b, []byte("package util\n\nfunc Run() {}\n"), 0644)
+
+	result := ExtractFiles([]string{a, b}, Options{})
+
+	if len(result.Files) != 2 {
+		t.Fatalf("expected 2 files, got %+v", result.Files)
+	}
+	// sha256 of "package main\n".
+	want := "df1d036cbbf3df46e2045071e082245ece204c7f53ecf0a4e022bff9bb228f47"
+	if f := result.Files[0]; f.File != a || f.Package != "main" || f.FileHash != want {
+		t.Errorf("unexpected file entry %+v", f)
+	}
+	if result.Files[0].FileHash == result.Files[1].FileHash {
+		t.Errorf("expected different files to hash differently")
+	}
+}
+
+func TestExtractSkipGenerated(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"gen.go":      "// Code generated by stringer; DO NOT EDIT.\n\npackage main\n\nfunc Gen() {}\n",
+		"header.go":   "// Copyright 2024.\n\n// Code generated by protoc-gen-go. DO NOT EDIT.\n// source: api.proto\n\npackage main\n\nfunc Proto() {}\n",
+		"mention.go":  "// Note: Code generated files say DO NOT EDIT.\n\npackage main\n\nfunc Mention() {}\n",
+		"late.go":     "package main\n\n// Code generated by hand. DO NOT EDIT.\nfunc Late() {}\n",
+		"noperiod.go": "// Code generated by tool. DO NOT EDIT\n\npackage main\n\nfunc NoPeriod() {}\n",
+	}
+	var paths []string
+	for _, name := range []string{"gen.go", "header.go", "mention.go", "late.go", "noperiod.go"} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(files[name]), 0644)
+		paths = append(paths, path)
+	}
+
+	result := ExtractFiles(paths, Options{SkipGenerated: true})
+
+	var names []string
+	for _, fn := range result.Functions {
+		names = append(names, fn.Name)
+	}
+	if got := strings.Join(names, ","); got != "Mention,Late,NoPeriod" {
+		t.Errorf("expected only hand-written functions Mention,Late,NoPeriod, got %s", got)
+	}
+}
+
+func TestExtractCRLFLineEndings(t *testing.T) {
+	dir := t.TempDir()
+	src := "package main\n\n// Sum adds.\nfunc Sum(a, b int) int {\n\tc := a + b\n\treturn c\n}\n\ntype Pair struct {\n\tA int\n\tB int\n}\n"
+	lf := filepath.Join(dir, "lf.go")
+	crlf := filepath.Join(dir, "crlf.go")
+	os.WriteFile(lf, []byte(src), 0644)
+	os.WriteFile(crlf, []byte(strings.ReplaceAll(src, "\n", "\r\n")), 0644)
+
+	want, err := extractFile(lf, Options{})
+	if err != nil {
+		t.Fatalf("extractFile(lf, Options{}) failed: %v", err)
+	}
+	got, err := extractFile(crlf, Options{})
+	if err != nil {
+		t.Fatalf("extractFile(crlf, Options{}) failed: %v", err)
+	}
+	wf, gf := want.Functions[0], got.Functions[0]
+	if gf.Line != wf.Line || gf.EndLine != wf.EndLine || gf.LOC != wf.LOC {
+		t.Errorf("expected line %d-%d LOC %d, got %d-%d LOC %d", wf.Line, wf.EndLine, wf.LOC, gf.Line, gf.EndLine, gf.LOC)
+	}
+	if ws, gs := want.Structs[0], got.Structs[0]; gs.Line != ws.Line || gs.LOC != ws.LOC {
+		t.Errorf("expected struct line %d LOC %d, got %d LOC %d", ws.Line, ws.LOC, gs.Line, gs.LOC)
+	}
+}
+
+func TestExtractDocComments(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "doc.go")
+	os.WriteFile(src, []byte(`package main
+
+// Server serves.
+// It has two doc lines.
+type Server struct{}
+
+type (
+	// Reader reads.
+	Reader interface{ Read() }
+
+	Writer interface{ Write() }
+)
+
+/*
+Run starts
+everything.
+*/
+func Run() {}
+
+func undocumented() {}
+
+// Handler handles.
+var Handler = func() {}
+
+const (
+	// Max is the limit.
+	Max = 10
+)
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if s := result.Structs[0]; s.DocLines != 2 || s.Doc != "Server serves.\nIt has two doc lines.\n" {
+		t.Errorf("expected 2 doc lines for Server, got %d %q", s.DocLines, s.Doc)
+	}
+	if r, w := result.Interfaces[0], result.Interfaces[1]; r.DocLines != 1 || w.DocLines != 0 {
+		t.Errorf("expected Reader 1 and Writer 0 doc lines, got %d and %d", r.DocLines, w.DocLines)
+	}
+	expected := map[string]int{"Run": 4, "undocumented": 0, "Handler": 1}
+	for _, fn := range result.Functions {
+		if fn.DocLines != expected[fn.Name] {
+			t.Errorf("%s: expected %d doc lines, got %d", fn.Name, expected[fn.Name], fn.DocLines)
+		}
+	}
+	if v := result.Values; len(v) != 2 || v[1].Name != "Max" || v[1].DocLines != 1 || v[0].DocLines != 1 {
+		t.Errorf("expected Handler and Max with 1 doc line each, got %+v", v)
+	}
+}
+
+func TestExtractPackageClause(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "pkg.go")
+	// The body does not parse; only the package clause is read.
+	os.WriteFile(src, []byte("// Package server serves.\npackage server\n\nfunc broken( {\n"), 0644)
+
+	fi, err := ExtractPackageClause(src)
+	if err != nil {
+		t.Fatalf("extractPackageClause failed: %v", err)
+	}
+	if fi.File != src || fi.Package != "server" {
+		t.Errorf("expected %s in package server, got %+v", src, fi)
+	}
+
+	os.WriteFile(src, []byte("func main() {}\n"), 0644)
+	if _, err := ExtractPackageClause(src); err == nil {
+		t.Errorf("expected error for a file without a package clause")
+	}
+}
+
+func TestTypeStringArrayLength(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "arrays.go")
+	os.WriteFile(src, []byte(`package main
+
+const N = 4
+
+var cfg struct {
+	ID   [16]byte
+	Tags []string
+	Grid [N * 2][N]int
+}
+
+func Hash(sum [32]byte, parts [][8]byte, rest ...[2]int) {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if got, want := result.Values[1].Type, "struct{ID [16]byte; Tags []string; Grid [N * 2][N]int}"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	expected := []string{"[32]byte", "[][8]byte", "...[2]int"}
+	if got := result.Functions[0].ParamTypes; strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected param types %v, got %v", expected, got)
+	}
+}
+
+func TestExtractTypeParams(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "generic.go")
+	os.WriteFile(src, []byte(`package main
+
+import "golang.org/x/exp/constraints"
+
+type Number interface {
+	~int | ~int64 | float64
+}
+
+type Map[K comparable, V any] struct{}
+
+func Max[T constraints.Ordered](a, b T) T { return a }
+
+func Sum[T int | string, S ~[]T](s S) T { var z T; return z }
+
+func Plain() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := map[string]string{
+		"Max":   "T constraints.Ordered",
+		"Sum":   "T int | string; S ~[]T",
+		"Plain": "",
+	}
+	for _, fn := range result.Functions {
+		if got := strings.Join(fn.TypeParams, "; "); got != expected[fn.Name] {
+			t.Errorf("%s: expected type params %q, got %q", fn.Name, expected[fn.Name], got)
+		}
+	}
+	if got := strings.Join(result.Structs[0].TypeParams, "; "); got != "K comparable; V any" {
+		t.Errorf("expected Map type params, got %q", got)
+	}
+	if got := result.Interfaces[0].TypeParams; got == nil || len(got) != 0 {
+		t.Errorf("expected empty type params for Number, got %v", got)
+	}
+}
+
+func TestExtractColumnsAndOffsets(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "pos.go")
+	code := "package main\n\ntype (\n\tServer struct{}\n\tRunner interface{}\n)\n\nconst Max, Min = 1, 0\n\nfunc Run() {}\n"
+	os.WriteFile(src, []byte(code), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	check := func(name string, line, column, offset int, wantLine int, wantText string) {
+		t.Helper()
+		if line != wantLine || !strings.HasPrefix(code[offset:], wantText) || column != offset-strings.LastIndex(code[:offset], "\n") {
+			t.Errorf("%s: unexpected position %d:%d (offset %d)", name, line, column, offset)
+		}
+	}
+	s, i, fn := result.Structs[0], result.Interfaces[0], result.Functions[0]
+	check("Server", s.Line, s.Column, s.Offset, 4, "Server")
+	check("Runner", i.Line, i.Column, i.Offset, 5, "Runner")
+	check("Run", fn.Line, fn.Column, fn.Offset, 10, "func Run")
+	check("Min", result.Values[1].Line, result.Values[1].Column, result.Values[1].Offset, 8, "Min")
+	if s.Column != 2 || result.Values[1].Column != 12 {
+		t.Errorf("expected columns 2 and 12, got %d and %d", s.Column, result.Values[1].Column)
+	}
+}
+
+func TestExtractStripsByteOrderMark(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "bom.go")
+	os.WriteFile(src, []byte("\uFEFFpackage main\n\nfunc Hello() { println(\"hi\") }\n"), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	fn := result.Functions[0]
+	if fn.Body != `{ println("hi") }` {
+		t.Errorf("expected body without stray characters, got %q", fn.Body)
+	}
+	if fn.Line != 3 || fn.Column != 1 {
+		t.Errorf("expected Hello at 3:1, got %d:%d", fn.Line, fn.Column)
+	}
+}
+
+func TestExtractFilesCountsFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.go")
+	bad := filepath.Join(dir, "bad.go")
+	os.WriteFile(good, []byte("package main\n\nfunc Good() {}\n"), 0644)
+	os.WriteFile(bad, []byte("package main\n\nfunc Bad( {\n"), 0644)
+
+	var failed []string
+	missing := filepath.Join(dir, "missing.go")
+	result := ExtractFiles([]string{good, bad, missing}, Options{FileError: recordFailures(&failed)})
+
+	if len(failed) != 2 || failed[0] != bad || failed[1] != missing {
+		t.Errorf("expected bad.go and missing.go to be reported, got %v", failed)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].Name != "Good" {
+		t.Errorf("expected Good to still be extracted, got %v", result.Functions)
+	}
+}
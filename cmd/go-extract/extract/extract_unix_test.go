@@ -0,0 +1,45 @@
+//go:build unix
+
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExtractFileTimeout(t *testing.T) {
+	dir := t.TempDir()
+	// Opening a FIFO with no writer blocks, standing in for a file whose
+	// extraction never finishes.
+	fifo := filepath.Join(dir, "stuck.go")
+	if err := syscall.Mkfifo(fifo, 0644); err != nil {
+		t.Skipf("mkfifo unavailable: %v", err)
+	}
+	ok := filepath.Join(dir, "ok.go")
+	os.WriteFile(ok, []byte("package main\n\nfunc Run() {}\n"), 0644)
+
+	opts := Options{Timeout: 50 * time.Millisecond}
+	defer func() {
+		// Unblock the abandoned reader.
+		if w, err := os.OpenFile(fifo, os.O_WRONLY, 0); err == nil {
+			w.Close()
+		}
+	}()
+
+	if _, err := extractFile(fifo, opts); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+	var failed []string
+	opts.FileError = recordFailures(&failed)
+	result := ExtractFiles([]string{fifo, ok}, opts)
+	if len(failed) != 1 || failed[0] != fifo {
+		t.Errorf("expected the stuck file to be reported, got %v", failed)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].Name != "Run" {
+		t.Errorf("expected extraction to continue with ok.go, got %+v", result.Functions)
+	}
+}
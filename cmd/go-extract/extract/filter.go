@@ -0,0 +1,124 @@
+package extract
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// keep returns the items for which pred is true, as a non-nil slice.
+func keep[T any](items []T, pred func(T) bool) []T {
+	kept := []T{}
+	for _, item := range items {
+		if pred(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// FilterByName keeps only the functions, structs, interfaces, and values whose
+// names match re.
+func FilterByName(result *ExtractResult, re *regexp.Regexp) {
+	result.Functions = keep(result.Functions, func(fn FunctionInfo) bool { return re.MatchString(fn.Name) })
+	result.Structs = keep(result.Structs, func(s StructInfo) bool { return re.MatchString(s.Name) })
+	result.Interfaces = keep(result.Interfaces, func(iface InterfaceInfo) bool { return re.MatchString(iface.Name) })
+	result.Values = keep(result.Values, func(v ValueInfo) bool { return re.MatchString(v.Name) })
+}
+
+// FilterBySymbol keeps only the functions, methods, structs, interfaces, and
+// values named exactly name. Methods of different receivers may all match.
+func FilterBySymbol(result *ExtractResult, name string) {
+	result.Functions = keep(result.Functions, func(fn FunctionInfo) bool { return fn.Name == name })
+	result.Structs = keep(result.Structs, func(s StructInfo) bool { return s.Name == name })
+	result.Interfaces = keep(result.Interfaces, func(iface InterfaceInfo) bool { return iface.Name == name })
+	result.Values = keep(result.Values, func(v ValueInfo) bool { return v.Name == name })
+}
+
+// filterExported keeps only the exported functions, structs, interfaces, and
+// values. Exported methods are kept only when their receiver type is exported
+// too.
+func filterExported(result *ExtractResult) {
+	result.Functions = keep(result.Functions, func(fn FunctionInfo) bool {
+		return fn.Exported && (fn.Receiver == "" || isExported(fn.Receiver))
+	})
+	result.Structs = keep(result.Structs, func(s StructInfo) bool { return s.Exported })
+	result.Interfaces = keep(result.Interfaces, func(iface InterfaceInfo) bool { return isExported(iface.Name) })
+	result.Values = keep(result.Values, func(v ValueInfo) bool { return v.Exported })
+}
+
+// ParseLineRange parses a -lines value of the form START:END into an
+// inclusive range of 1-based line numbers.
+func ParseLineRange(s string) (start, end int, err error) {
+	startStr, endStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected START:END, got %q", s)
+	}
+	start, err = strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start line %q", startStr)
+	}
+	end, err = strconv.Atoi(endStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid end line %q", endStr)
+	}
+	if start < 1 || end < start {
+		return 0, 0, fmt.Errorf("invalid range %d:%d", start, end)
+	}
+	return start, end, nil
+}
+
+// FilterByLines keeps only the functions, structs, interfaces, and values whose
+// declaration starts within the inclusive line range [start, end]. Entities
+// that start inside the range but extend past it are kept.
+func FilterByLines(result *ExtractResult, start, end int) {
+	inRange := func(line int) bool { return line >= start && line <= end }
+	result.Functions = keep(result.Functions, func(fn FunctionInfo) bool { return inRange(fn.Line) })
+	result.Structs = keep(result.Structs, func(s StructInfo) bool { return inRange(s.Line) })
+	result.Interfaces = keep(result.Interfaces, func(iface InterfaceInfo) bool { return inRange(iface.Line) })
+	result.Values = keep(result.Values, func(v ValueInfo) bool { return inRange(v.Line) })
+}
+
+// FilterByUses keeps only the functions whose body has an identifier for
+// which match is true. Every identifier counts, whether it names a variable,
+// function, type, package, or the field or method of a selector, and
+// bodies of function literals count toward the enclosing function. Matching
+// is by name only, so a local variable shadowing a global matches too.
+func FilterByUses(result *ExtractResult, match func(string) bool) {
+	result.Functions = keep(result.Functions, func(fn FunctionInfo) bool {
+		for name := range fn.identifiers {
+			if match(name) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// bodyIdentifiers returns the set of identifier names in body.
+func bodyIdentifiers(body *ast.BlockStmt) map[string]bool {
+	names := make(map[string]bool)
+	if body == nil {
+		return names
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			names[id.Name] = true
+		}
+		return true
+	})
+	return names
+}
+
+// FilterBySize keeps only the functions with at least minLOC lines and a
+// cyclomatic complexity of at least minComplexity; both must hold. Zero
+// disables either bound. Complexity is computed for every function, with
+// bodyless declarations scoring 1, so the bound never silently drops
+// functions for want of data. Structs, interfaces, and values are untouched.
+func FilterBySize(result *ExtractResult, minLOC, minComplexity int) {
+	result.Functions = keep(result.Functions, func(fn FunctionInfo) bool {
+		return fn.LOC >= minLOC && fn.Complexity >= minComplexity
+	})
+}
@@ -0,0 +1,196 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFilterByName(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "HandleLogin"},
+			{Name: "helper"},
+		},
+		Structs: []StructInfo{
+			{Name: "HandlerConfig"},
+			{Name: "Server"},
+		},
+		Interfaces: []InterfaceInfo{
+			{Name: "Handler"},
+			{Name: "Reader"},
+		},
+	}
+
+	FilterByName(result, regexp.MustCompile("^Handle"))
+
+	if len(result.Functions) != 1 || result.Functions[0].Name != "HandleLogin" {
+		t.Errorf("expected only HandleLogin, got %v", result.Functions)
+	}
+	if len(result.Structs) != 1 || result.Structs[0].Name != "HandlerConfig" {
+		t.Errorf("expected only HandlerConfig, got %v", result.Structs)
+	}
+	if len(result.Interfaces) != 1 || result.Interfaces[0].Name != "Handler" {
+		t.Errorf("expected only Handler, got %v", result.Interfaces)
+	}
+}
+
+func TestFilterByNameNoMatches(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{{Name: "helper"}},
+	}
+
+	FilterByName(result, regexp.MustCompile("^Handle"))
+
+	if result.Functions == nil || len(result.Functions) != 0 {
+		t.Errorf("expected empty non-nil functions, got %v", result.Functions)
+	}
+	if result.Structs == nil || result.Interfaces == nil {
+		t.Errorf("expected empty non-nil structs and interfaces")
+	}
+}
+
+func TestParseLineRange(t *testing.T) {
+	start, end, err := ParseLineRange("10:20")
+	if err != nil || start != 10 || end != 20 {
+		t.Errorf("expected 10:20, got %d:%d (%v)", start, end, err)
+	}
+	for _, bad := range []string{"10", "a:20", "10:b", "20:10", "0:5"} {
+		if _, _, err := ParseLineRange(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestFilterByLines(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "before", Line: 5, EndLine: 12},
+			{Name: "spanning", Line: 15, EndLine: 40},
+			{Name: "after", Line: 31},
+		},
+		Structs:    []StructInfo{{Name: "Inside", Line: 10}},
+		Interfaces: []InterfaceInfo{{Name: "Outside", Line: 50}},
+	}
+
+	FilterByLines(result, 10, 30)
+
+	if len(result.Functions) != 1 || result.Functions[0].Name != "spanning" {
+		t.Errorf("expected only spanning, got %v", result.Functions)
+	}
+	if len(result.Structs) != 1 {
+		t.Errorf("expected Inside to be kept, got %v", result.Structs)
+	}
+	if len(result.Interfaces) != 0 {
+		t.Errorf("expected Outside to be dropped, got %v", result.Interfaces)
+	}
+}
+
+func TestFilterBySymbol(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "Start", Receiver: "Server"},
+			{Name: "Start", Receiver: "Worker"},
+			{Name: "StartAll"},
+		},
+		Structs:    []StructInfo{{Name: "Server"}},
+		Interfaces: []InterfaceInfo{{Name: "Starter"}},
+	}
+
+	FilterBySymbol(result, "Start")
+
+	if len(result.Functions) != 2 {
+		t.Errorf("expected both Start methods, got %v", result.Functions)
+	}
+	if !(&ExtractResult{}).IsEmpty() || result.IsEmpty() {
+		t.Errorf("unexpected isEmpty results")
+	}
+
+	FilterBySymbol(result, "Missing")
+	if !result.IsEmpty() {
+		t.Errorf("expected no matches for Missing")
+	}
+}
+
+func TestFilterBySize(t *testing.T) {
+	newSizes := func() *ExtractResult {
+		return &ExtractResult{
+			Functions: []FunctionInfo{
+				{Name: "Long", LOC: 80, Complexity: 2},
+				{Name: "Tangled", LOC: 20, Complexity: 15},
+				{Name: "Both", LOC: 60, Complexity: 12},
+				{Name: "Tiny", LOC: 3, Complexity: 1},
+			},
+			Structs: []StructInfo{{Name: "Config"}},
+		}
+	}
+	names := func(result *ExtractResult) string {
+		var names []string
+		for _, fn := range result.Functions {
+			names = append(names, fn.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	tests := []struct {
+		minLOC, minComplexity int
+		expected              string
+	}{
+		{50, 0, "Long,Both"},
+		{0, 10, "Tangled,Both"},
+		{50, 10, "Both"},
+	}
+	for _, tt := range tests {
+		result := newSizes()
+		FilterBySize(result, tt.minLOC, tt.minComplexity)
+		if got := names(result); got != tt.expected {
+			t.Errorf("min-loc %d, min-complexity %d: expected %s, got %s", tt.minLOC, tt.minComplexity, tt.expected, got)
+		}
+		if len(result.Structs) != 1 {
+			t.Errorf("expected structs to be untouched, got %v", result.Structs)
+		}
+	}
+}
+
+func TestFilterByUses(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "uses.go")
+	os.WriteFile(src, []byte(`package main
+
+var counter int
+
+func Inc() { counter++ }
+
+func Read() int {
+	return load(func() int { return counter })
+}
+
+func Reset(c *Config) { c.counter = 0 }
+
+func Other(counters []int) int { return len(counters) }
+
+func Decl(counter int)
+`), 0644)
+
+	names := func(result *ExtractResult) string {
+		var names []string
+		for _, fn := range result.Functions {
+			names = append(names, fn.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	result := ExtractFiles([]string{src}, Options{})
+	FilterByUses(result, func(name string) bool { return name == "counter" })
+	if got := names(result); got != "Inc,Read,Reset" {
+		t.Errorf("expected Inc,Read,Reset, got %s", got)
+	}
+
+	result = ExtractFiles([]string{src}, Options{})
+	FilterByUses(result, regexp.MustCompile("^(?:count.*s)$").MatchString)
+	if got := names(result); got != "Other" {
+		t.Errorf("expected Other, got %s", got)
+	}
+}
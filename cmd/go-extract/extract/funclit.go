@@ -0,0 +1,91 @@
+package extract
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// extractFuncLits extracts a FunctionInfo for every function literal inside
+// decl, named the way the Go toolchain names closures: Outer.func1,
+// Outer.func2, and Outer.func1.1 for a literal nested in the first. Methods
+// are prefixed with their receiver type (Server.Start.func1). Literals are
+// never exported, and each records the outer variables it captures.
+func extractFuncLits(fset *token.FileSet, decl *ast.FuncDecl, filename, src string, scope *fileScope) []FunctionInfo {
+	if decl.Body == nil {
+		return nil
+	}
+	prefix := decl.Name.Name
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		prefix = receiverTypeName(decl.Recv.List[0].Type) + "." + prefix
+	}
+
+	var lits []FunctionInfo
+	var visit func(root ast.Node, parent, sep string)
+	visit = func(root ast.Node, parent, sep string) {
+		n := 0
+		ast.Inspect(root, func(node ast.Node) bool {
+			lit, ok := node.(*ast.FuncLit)
+			if !ok {
+				return true
+			}
+			n++
+			name := parent + sep + strconv.Itoa(n)
+			fn := &ast.FuncDecl{Name: ast.NewIdent(name), Type: lit.Type, Body: lit.Body}
+			fi := extractFunction(fset, fn, filename, src, scope)
+			fi.Exported = false
+			fi.IsFuncLit = true
+			fi.Captures = funcLitCaptures(decl, lit)
+			lits = append(lits, fi)
+			visit(lit.Body, name, ".")
+			return false
+		})
+	}
+	visit(decl.Body, prefix, ".func")
+	return lits
+}
+
+// funcLitCaptures returns the sorted, distinct names lit uses that are
+// declared in decl outside of lit (see walkDecls): the variables a closure
+// captures. Resolution is by name, so a name lit redeclares anywhere inside
+// itself is not reported, and field keys in composite literals are ignored.
+func funcLitCaptures(decl *ast.FuncDecl, lit *ast.FuncLit) []string {
+	outer := make(map[string]bool)
+	inner := make(map[string]bool)
+	walkDecls(decl, func(name *ast.Ident) {
+		if name.Pos() >= lit.Pos() && name.End() <= lit.End() {
+			inner[name.Name] = true
+		} else {
+			outer[name.Name] = true
+		}
+	})
+
+	seen := make(map[string]bool)
+	var use func(n ast.Node) bool
+	use = func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(node.X, use)
+			return false
+		case *ast.KeyValueExpr:
+			if _, ok := node.Key.(*ast.Ident); ok {
+				ast.Inspect(node.Value, use)
+				return false
+			}
+		case *ast.Ident:
+			if outer[node.Name] && !inner[node.Name] {
+				seen[node.Name] = true
+			}
+		}
+		return true
+	}
+	ast.Inspect(lit.Body, use)
+
+	captures := make([]string, 0, len(seen))
+	for name := range seen {
+		captures = append(captures, name)
+	}
+	sort.Strings(captures)
+	return captures
+}
@@ -0,0 +1,81 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractFuncLits(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "lits.go")
+	os.WriteFile(src, []byte(`package main
+
+func Run(items []string, out chan string) {
+	prefix := "> "
+	for i, item := range items {
+		go func() {
+			out <- prefix + item
+			log := func(msg string) {
+				println(i, msg, cfg.Name)
+			}
+			log(T{Name: prefix}.Name)
+		}()
+	}
+	done := func(n int) int { return n }
+	_ = done
+}
+
+func (s *Server) Start() {
+	go func() { s.run() }()
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{IncludeFuncLits: true})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"Run.func1":          "i,item,out,prefix",
+		"Run.func1.1":        "i",
+		"Run.func2":          "",
+		"Server.Start.func1": "s",
+	}
+	lits := 0
+	for _, fn := range result.Functions {
+		if !fn.IsFuncLit {
+			continue
+		}
+		lits++
+		want, ok := expected[fn.Name]
+		if !ok {
+			t.Errorf("unexpected function literal %s", fn.Name)
+			continue
+		}
+		if got := strings.Join(fn.Captures, ","); got != want {
+			t.Errorf("%s: expected captures %q, got %q", fn.Name, want, got)
+		}
+		if fn.Exported || fn.Receiver != "" {
+			t.Errorf("%s: expected unexported literal without receiver", fn.Name)
+		}
+	}
+	if lits != len(expected) {
+		t.Errorf("expected %d function literals, got %d", len(expected), lits)
+	}
+}
+
+func TestExtractFuncLitsDisabled(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "lits.go")
+	os.WriteFile(src, []byte("package main\n\nfunc Run() { go func() {}() }\n"), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Functions) != 1 {
+		t.Errorf("expected literals to be skipped by default, got %d functions", len(result.Functions))
+	}
+}
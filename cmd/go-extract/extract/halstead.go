@@ -0,0 +1,121 @@
+package extract
+
+import (
+	"go/ast"
+	"go/token"
+	"math"
+)
+
+// HalsteadMetrics holds Halstead's software-science measures for a function body.
+type HalsteadMetrics struct {
+	DistinctOperators int     `json:"distinct_operators"`
+	DistinctOperands  int     `json:"distinct_operands"`
+	TotalOperators    int     `json:"total_operators"`
+	TotalOperands     int     `json:"total_operands"`
+	Vocabulary        int     `json:"vocabulary"`
+	Length            int     `json:"length"`
+	Volume            float64 `json:"volume"`
+	Difficulty        float64 `json:"difficulty"`
+	Effort            float64 `json:"effort"`
+}
+
+// halstead computes Halstead metrics for body. Go AST nodes are classified as:
+//
+//   - Operands: identifiers (by name) and basic literals (by source text).
+//   - Operators: operator tokens of binary, unary, assignment, and inc/dec
+//     statements; statement keywords (if, for, range, switch, select, case,
+//     return, go, defer, break, continue, goto, fallthrough, and func for
+//     literals); and the structural operators call "()", index "[]", slice
+//     "[:]", selector ".", dereference "*", composite literal "{}", type
+//     assertion ".()", key-value ":", and send "<-".
+//
+// From the distinct (n1, n2) and total (N1, N2) operator and operand counts:
+// vocabulary n = n1+n2, length N = N1+N2, volume V = N*log2(n), difficulty
+// D = (n1/2)*(N2/n2), and effort E = D*V. A nil body yields all zeros.
+func halstead(body *ast.BlockStmt) HalsteadMetrics {
+	if body == nil {
+		return HalsteadMetrics{}
+	}
+	operators := make(map[string]int)
+	operands := make(map[string]int)
+	op := func(s string) { operators[s]++ }
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.Ident:
+			operands[node.Name]++
+		case *ast.BasicLit:
+			operands[node.Value]++
+		case *ast.BinaryExpr:
+			op(node.Op.String())
+		case *ast.UnaryExpr:
+			op(node.Op.String())
+		case *ast.AssignStmt:
+			op(node.Tok.String())
+		case *ast.IncDecStmt:
+			op(node.Tok.String())
+		case *ast.SendStmt:
+			op(token.ARROW.String())
+		case *ast.CallExpr:
+			op("()")
+		case *ast.IndexExpr, *ast.IndexListExpr:
+			op("[]")
+		case *ast.SliceExpr:
+			op("[:]")
+		case *ast.SelectorExpr:
+			op(".")
+		case *ast.StarExpr:
+			op("*")
+		case *ast.CompositeLit:
+			op("{}")
+		case *ast.TypeAssertExpr:
+			op(".()")
+		case *ast.KeyValueExpr:
+			op(":")
+		case *ast.IfStmt:
+			op("if")
+		case *ast.ForStmt:
+			op("for")
+		case *ast.RangeStmt:
+			op("range")
+		case *ast.SwitchStmt, *ast.TypeSwitchStmt:
+			op("switch")
+		case *ast.SelectStmt:
+			op("select")
+		case *ast.CaseClause, *ast.CommClause:
+			op("case")
+		case *ast.ReturnStmt:
+			op("return")
+		case *ast.GoStmt:
+			op("go")
+		case *ast.DeferStmt:
+			op("defer")
+		case *ast.BranchStmt:
+			op(node.Tok.String())
+		case *ast.FuncLit:
+			op("func")
+		}
+		return true
+	})
+
+	m := HalsteadMetrics{
+		DistinctOperators: len(operators),
+		DistinctOperands:  len(operands),
+	}
+	for _, count := range operators {
+		m.TotalOperators += count
+	}
+	for _, count := range operands {
+		m.TotalOperands += count
+	}
+	m.Vocabulary = m.DistinctOperators + m.DistinctOperands
+	m.Length = m.TotalOperators + m.TotalOperands
+	if m.Vocabulary > 0 {
+		m.Volume = float64(m.Length) * math.Log2(float64(m.Vocabulary))
+	}
+	if m.DistinctOperands > 0 {
+		m.Difficulty = float64(m.DistinctOperators) / 2 * float64(m.TotalOperands) / float64(m.DistinctOperands)
+	}
+	m.Effort = m.Difficulty * m.Volume
+	return m
+}
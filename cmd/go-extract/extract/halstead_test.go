@@ -0,0 +1,53 @@
+package extract
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHalstead(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "add.go")
+	os.WriteFile(src, []byte(`package main
+
+func Add(a, b int) int {
+	c := a + b
+	return c
+}
+
+func Empty() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	h := result.Functions[0].Halstead
+	// Operators: ":=", "+", "return". Operands: c, a, b, c.
+	if h.DistinctOperators != 3 || h.TotalOperators != 3 {
+		t.Errorf("expected 3 distinct/total operators, got %d/%d", h.DistinctOperators, h.TotalOperators)
+	}
+	if h.DistinctOperands != 3 || h.TotalOperands != 4 {
+		t.Errorf("expected 3 distinct and 4 total operands, got %d/%d", h.DistinctOperands, h.TotalOperands)
+	}
+	if h.Vocabulary != 6 || h.Length != 7 {
+		t.Errorf("expected vocabulary 6 and length 7, got %d and %d", h.Vocabulary, h.Length)
+	}
+	wantVolume := 7 * math.Log2(6)
+	if math.Abs(h.Volume-wantVolume) > 1e-9 {
+		t.Errorf("expected volume %f, got %f", wantVolume, h.Volume)
+	}
+	wantDifficulty := 3.0 / 2 * 4 / 3
+	if math.Abs(h.Difficulty-wantDifficulty) > 1e-9 {
+		t.Errorf("expected difficulty %f, got %f", wantDifficulty, h.Difficulty)
+	}
+	if math.Abs(h.Effort-wantDifficulty*wantVolume) > 1e-9 {
+		t.Errorf("expected effort %f, got %f", wantDifficulty*wantVolume, h.Effort)
+	}
+
+	if empty := result.Functions[1].Halstead; empty != (HalsteadMetrics{}) {
+		t.Errorf("expected zero metrics for empty body, got %+v", empty)
+	}
+}
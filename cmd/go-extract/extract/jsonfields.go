@@ -0,0 +1,74 @@
+package extract
+
+import (
+	"go/ast"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONField describes how encoding/json serializes one struct field.
+type JSONField struct {
+	Field     string `json:"field"`
+	Key       string `json:"key"`
+	Type      string `json:"type"`
+	OmitEmpty bool   `json:"omit_empty"`
+}
+
+// jsonFields returns the fields of a struct that encoding/json serializes,
+// in declaration order: exported named fields keyed by the name in their
+// json tag, or by their Go name when the tag gives none. Fields tagged
+// json:"-" are excluded; json:"-," keys a field as "-". Unexported fields are
+// never serialized. Embedded fields are included only when their tag names
+// them, since an untagged embedded struct's fields are promoted into the
+// parent and those are not resolved here.
+func jsonFields(fields *ast.FieldList) []JSONField {
+	var result []JSONField
+	if fields == nil {
+		return result
+	}
+	for _, field := range fields.List {
+		tag := ""
+		if field.Tag != nil {
+			if raw, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(raw).Get("json")
+			}
+		}
+		if tag == "-" {
+			continue
+		}
+		key, opts, _ := strings.Cut(tag, ",")
+		omitEmpty := false
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "omitempty" {
+				omitEmpty = true
+			}
+		}
+		typ := typeString(field.Type)
+
+		if len(field.Names) == 0 {
+			if key == "" {
+				continue
+			}
+			// The field is named after its type: *pkg.Base[T] is Base.
+			name := strings.TrimPrefix(typ, "*")
+			if i := strings.Index(name, "["); i >= 0 {
+				name = name[:i]
+			}
+			name = name[strings.LastIndex(name, ".")+1:]
+			result = append(result, JSONField{Field: name, Key: key, Type: typ, OmitEmpty: omitEmpty})
+			continue
+		}
+		for _, name := range field.Names {
+			if !isExported(name.Name) {
+				continue
+			}
+			k := key
+			if k == "" {
+				k = name.Name
+			}
+			result = append(result, JSONField{Field: name.Name, Key: k, Type: typ, OmitEmpty: omitEmpty})
+		}
+	}
+	return result
+}
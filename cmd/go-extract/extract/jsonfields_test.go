@@ -0,0 +1,53 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractJSONFields(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "user.go")
+	os.WriteFile(src, []byte(`package user
+
+type User struct {
+	ID        int       `+"`json:\"id\"`"+`
+	Name      string    `+"`json:\"name,omitempty\" db:\"name\"`"+`
+	Password  string    `+"`json:\"-\"`"+`
+	Dash      string    `+"`json:\"-,\"`"+`
+	Email     string
+	A, B      bool      `+"`json:\",omitempty\"`"+`
+	internal  string    `+"`json:\"internal\"`"+`
+	Meta      `+"`json:\"meta\"`"+`
+	*base.Audit
+	Created   time.Time `+"`yaml:\"created\"`"+`
+}
+
+type Empty struct {
+	secret string
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := []JSONField{
+		{Field: "ID", Key: "id", Type: "int"},
+		{Field: "Name", Key: "name", Type: "string", OmitEmpty: true},
+		{Field: "Dash", Key: "-", Type: "string"},
+		{Field: "Email", Key: "Email", Type: "string"},
+		{Field: "A", Key: "A", Type: "bool", OmitEmpty: true},
+		{Field: "B", Key: "B", Type: "bool", OmitEmpty: true},
+		{Field: "Meta", Key: "meta", Type: "Meta"},
+		{Field: "Created", Key: "Created", Type: "time.Time"},
+	}
+	if !reflect.DeepEqual(result.Structs[0].JSONFields, expected) {
+		t.Errorf("expected JSON fields %+v, got %+v", expected, result.Structs[0].JSONFields)
+	}
+	if result.Structs[1].JSONFields != nil {
+		t.Errorf("expected no JSON fields for Empty, got %+v", result.Structs[1].JSONFields)
+	}
+}
@@ -0,0 +1,135 @@
+package extract
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+	"strconv"
+)
+
+// basicLayouts gives the size and alignment in bytes of predeclared types on
+// a 64-bit platform.
+var basicLayouts = map[string][2]int{
+	"bool": {1, 1}, "int8": {1, 1}, "uint8": {1, 1}, "byte": {1, 1},
+	"int16": {2, 2}, "uint16": {2, 2},
+	"int32": {4, 4}, "uint32": {4, 4}, "rune": {4, 4}, "float32": {4, 4},
+	"int": {8, 8}, "uint": {8, 8}, "int64": {8, 8}, "uint64": {8, 8},
+	"uintptr": {8, 8}, "float64": {8, 8}, "complex64": {8, 4},
+	"complex128": {16, 8}, "string": {16, 8}, "error": {16, 8}, "any": {16, 8},
+}
+
+// pointerSize is the size and alignment assumed for pointers and for types
+// whose layout is unknown.
+const pointerSize = 8
+
+// fieldLayout is the estimated size and alignment of one struct field.
+type fieldLayout struct {
+	name        string
+	size, align int
+}
+
+// typeLayout estimates the size and alignment of a type expression on a
+// 64-bit platform without type checking. Named types other than the
+// predeclared ones, including those from other packages, and arrays whose
+// length is not a literal are assumed to be pointer-sized.
+func typeLayout(expr ast.Expr) (size, align int) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if l, ok := basicLayouts[t.Name]; ok {
+			return l[0], l[1]
+		}
+	case *ast.ParenExpr:
+		return typeLayout(t.X)
+	case *ast.InterfaceType:
+		return 16, pointerSize
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return 24, pointerSize
+		}
+		lit, ok := t.Len.(*ast.BasicLit)
+		if !ok || lit.Kind != token.INT {
+			break
+		}
+		n, err := strconv.ParseInt(lit.Value, 0, 64)
+		if err != nil {
+			break
+		}
+		elemSize, elemAlign := typeLayout(t.Elt)
+		return int(n) * elemSize, elemAlign
+	case *ast.StructType:
+		return structLayout(fieldLayouts(t.Fields))
+	}
+	return pointerSize, pointerSize
+}
+
+// fieldLayouts estimates the layout of each field in fields, in declaration
+// order. Embedded fields are named after their type.
+func fieldLayouts(fields *ast.FieldList) []fieldLayout {
+	var layouts []fieldLayout
+	for _, field := range fieldsOf(fields) {
+		size, align := typeLayout(field.Type)
+		if len(field.Names) == 0 {
+			layouts = append(layouts, fieldLayout{typeString(field.Type), size, align})
+			continue
+		}
+		for _, name := range field.Names {
+			layouts = append(layouts, fieldLayout{name.Name, size, align})
+		}
+	}
+	return layouts
+}
+
+// structLayout lays fields out in order, aligning each to its own alignment
+// and the total to the largest, and returns the resulting size and alignment.
+func structLayout(fields []fieldLayout) (size, align int) {
+	align = 1
+	for _, f := range fields {
+		size = alignUp(size, f.align) + f.size
+		if f.align > align {
+			align = f.align
+		}
+	}
+	return alignUp(size, align), align
+}
+
+// paddingThreshold is the padding, in bytes, from which a struct gets a
+// suggested field order.
+const paddingThreshold = 8
+
+// paddingBytes returns how many of size bytes fields leave unused.
+func paddingBytes(fields []fieldLayout, size int) int {
+	for _, f := range fields {
+		size -= f.size
+	}
+	return size
+}
+
+// suggestFieldOrder returns field names reordered by decreasing alignment
+// (then size), which minimizes padding when alignments are powers of two. It
+// returns nil unless the struct wastes at least paddingThreshold bytes and
+// the new order is actually smaller than size.
+func suggestFieldOrder(fields []fieldLayout, size int) []string {
+	if paddingBytes(fields, size) < paddingThreshold {
+		return nil
+	}
+	sorted := append([]fieldLayout{}, fields...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].align != sorted[j].align {
+			return sorted[i].align > sorted[j].align
+		}
+		return sorted[i].size > sorted[j].size
+	})
+	if newSize, _ := structLayout(sorted); newSize >= size {
+		return nil
+	}
+	names := make([]string, len(sorted))
+	for i, f := range sorted {
+		names[i] = f.name
+	}
+	return names
+}
+
+// alignUp rounds n up to a multiple of align.
+func alignUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
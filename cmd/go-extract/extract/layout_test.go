@@ -0,0 +1,97 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStructSizeEstimate(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "layout.go")
+	os.WriteFile(src, []byte(`package main
+
+type Empty struct{}
+
+type Padded struct {
+	A bool
+	B int64
+	C bool
+}
+
+type Mixed struct {
+	Name   string
+	Tags   []string
+	ID     [16]byte
+	Count  int32
+	Flag   bool
+	Next   *Mixed
+	Err    error
+	Point  struct{ X, Y float32 }
+	Remote time.Time
+	sync.Mutex
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := map[string]int{
+		"Empty": 0,
+		// 1 + 7 padding + 8 + 1 + 7 trailing padding.
+		"Padded": 24,
+		// 16 + 24 + 16 + 4 + 1 (+3) + 8 + 16 + 8 + 8 + 8.
+		"Mixed": 112,
+	}
+	for _, s := range result.Structs {
+		if s.SizeBytes != expected[s.Name] {
+			t.Errorf("%s: expected size %d, got %d", s.Name, expected[s.Name], s.SizeBytes)
+		}
+	}
+}
+
+func TestStructPadding(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "padding.go")
+	os.WriteFile(src, []byte(`package main
+
+type Wasteful struct {
+	A bool
+	B int64
+	C bool
+	D int32
+}
+
+type Tight struct {
+	B int64
+	D int32
+	A bool
+	C bool
+}
+
+type Small struct {
+	A bool
+	B int16
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	wasteful, tight, small := result.Structs[0], result.Structs[1], result.Structs[2]
+	if wasteful.SizeBytes != 24 || wasteful.PaddingBytes != 10 {
+		t.Errorf("expected Wasteful 24 bytes with 10 padding, got %d/%d", wasteful.SizeBytes, wasteful.PaddingBytes)
+	}
+	if got := strings.Join(wasteful.SuggestedFieldOrder, ","); got != "B,D,A,C" {
+		t.Errorf("expected suggested order B,D,A,C, got %q", got)
+	}
+	if tight.SizeBytes != 16 || tight.PaddingBytes != 2 || tight.SuggestedFieldOrder != nil {
+		t.Errorf("expected Tight 16 bytes, 2 padding, no suggestion, got %+v", tight)
+	}
+	if small.PaddingBytes != 1 || small.SuggestedFieldOrder != nil {
+		t.Errorf("expected Small below the threshold, got %+v", small)
+	}
+}
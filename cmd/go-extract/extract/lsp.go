@@ -0,0 +1,113 @@
+package extract
+
+import (
+	"io"
+	"sort"
+)
+
+// LSP SymbolKind values used in -format lsp output.
+const (
+	lspKindMethod    = 6
+	lspKindField     = 8
+	lspKindInterface = 11
+	lspKindFunction  = 12
+	lspKindStruct    = 23
+)
+
+// lspPosition is a zero-based LSP Position.
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// lspRange is an LSP Range spanning whole lines.
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+// documentSymbol mirrors the LSP DocumentSymbol shape.
+type documentSymbol struct {
+	Name           string           `json:"name"`
+	Kind           int              `json:"kind"`
+	Range          lspRange         `json:"range"`
+	SelectionRange lspRange         `json:"selectionRange"`
+	Children       []documentSymbol `json:"children,omitempty"`
+}
+
+// newDocumentSymbol builds a symbol spanning the 1-based lines start..end.
+// Only line granularity is known, so the selection range is the first line.
+func newDocumentSymbol(name string, kind, start, end int) documentSymbol {
+	if end < start {
+		end = start
+	}
+	return documentSymbol{
+		Name:           name,
+		Kind:           kind,
+		Range:          lspRange{lspPosition{Line: start - 1}, lspPosition{Line: end - 1}},
+		SelectionRange: lspRange{lspPosition{Line: start - 1}, lspPosition{Line: start - 1}},
+	}
+}
+
+// lspOutline groups result into per-file LSP document symbols, keyed by file.
+// Methods declared in the same file as their receiver struct become its
+// children, after its fields, including methods declared on an alias of it;
+// other methods are top-level symbols of their own file. Fields carry no
+// position of their own and take the struct's range, and interfaces span only
+// their declaration line.
+func lspOutline(result *ExtractResult) map[string][]documentSymbol {
+	type structKey struct{ file, name string }
+	outline := make(map[string][]documentSymbol)
+	structs := make(map[structKey]*documentSymbol)
+	aliases := newAliasTargets(result)
+
+	var structSyms []documentSymbol
+	var structFiles []string
+	for _, s := range result.Structs {
+		sym := newDocumentSymbol(s.Name, lspKindStruct, s.Line, s.Line+s.LOC-1)
+		for _, f := range s.Fields {
+			field := documentSymbol{Name: f, Kind: lspKindField, Range: sym.Range, SelectionRange: sym.SelectionRange}
+			sym.Children = append(sym.Children, field)
+		}
+		structSyms = append(structSyms, sym)
+		structFiles = append(structFiles, s.File)
+	}
+	for i := range structSyms {
+		structs[structKey{structFiles[i], structSyms[i].Name}] = &structSyms[i]
+	}
+
+	for _, fn := range result.Functions {
+		if fn.Receiver == "" {
+			outline[fn.File] = append(outline[fn.File], newDocumentSymbol(fn.Name, lspKindFunction, fn.Line, fn.EndLine))
+			continue
+		}
+		sym := newDocumentSymbol(fn.Name, lspKindMethod, fn.Line, fn.EndLine)
+		if parent, ok := structs[structKey{fn.File, aliases.receiverOf(fn).name}]; ok {
+			parent.Children = append(parent.Children, sym)
+			continue
+		}
+		outline[fn.File] = append(outline[fn.File], sym)
+	}
+	for i, sym := range structSyms {
+		outline[structFiles[i]] = append(outline[structFiles[i]], sym)
+	}
+	for _, iface := range result.Interfaces {
+		sym := newDocumentSymbol(iface.Name, lspKindInterface, iface.Line, iface.Line)
+		for _, m := range iface.Methods {
+			method := documentSymbol{Name: m, Kind: lspKindMethod, Range: sym.Range, SelectionRange: sym.SelectionRange}
+			sym.Children = append(sym.Children, method)
+		}
+		outline[iface.File] = append(outline[iface.File], sym)
+	}
+
+	for _, syms := range outline {
+		sort.SliceStable(syms, func(i, j int) bool { return syms[i].Range.Start.Line < syms[j].Range.Start.Line })
+	}
+	return outline
+}
+
+// WriteLSP writes result as a JSON object mapping each file to its LSP
+// document symbols.
+func WriteLSP(w io.Writer, result *ExtractResult) error {
+	return WriteIndentedJSON(w, lspOutline(result))
+}
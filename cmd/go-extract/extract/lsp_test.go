@@ -0,0 +1,89 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLSPOutline(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "outline.go")
+	os.WriteFile(src, []byte(`package main
+
+type Server struct {
+	Addr string
+}
+
+func (s *Server) Start() {
+}
+
+type Runner interface {
+	Run()
+}
+
+func main() {}
+`), 0644)
+
+	result := ExtractFiles([]string{src}, Options{})
+	outline := lspOutline(result)
+
+	syms := outline[src]
+	if len(syms) != 3 {
+		t.Fatalf("expected 3 top-level symbols, got %+v", syms)
+	}
+	server, runner, main := syms[0], syms[1], syms[2]
+	if server.Name != "Server" || server.Kind != lspKindStruct {
+		t.Errorf("expected struct Server first, got %+v", server)
+	}
+	if server.Range.Start.Line != 2 || server.Range.End.Line != 4 {
+		t.Errorf("expected Server range 2-4, got %+v", server.Range)
+	}
+	if len(server.Children) != 2 || server.Children[0].Kind != lspKindField || server.Children[1].Name != "Start" || server.Children[1].Kind != lspKindMethod {
+		t.Errorf("expected field Addr and method Start under Server, got %+v", server.Children)
+	}
+	if start := server.Children[1]; start.Range.Start.Line != 6 || start.Range.End.Line != 7 {
+		t.Errorf("expected Start range 6-7, got %+v", start.Range)
+	}
+	if runner.Kind != lspKindInterface || len(runner.Children) != 1 || runner.Children[0].Name != "Run" {
+		t.Errorf("expected interface Runner with method Run, got %+v", runner)
+	}
+	if main.Name != "main" || main.Kind != lspKindFunction {
+		t.Errorf("expected function main last, got %+v", main)
+	}
+}
+
+func TestLSPOutlineChildrenAreLeaves(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "outline.go")
+	os.WriteFile(src, []byte(`package main
+
+type Server struct {
+	A string
+	B int
+	C bool
+}
+
+type Runner interface {
+	Run()
+	Stop()
+}
+`), 0644)
+
+	result := ExtractFiles([]string{src}, Options{})
+	syms := lspOutline(result)[src]
+	if len(syms) != 2 {
+		t.Fatalf("expected 2 top-level symbols, got %+v", syms)
+	}
+	server, runner := syms[0], syms[1]
+	if len(server.Children) != 3 || len(runner.Children) != 2 {
+		t.Fatalf("expected 3 fields and 2 methods, got %+v and %+v", server.Children, runner.Children)
+	}
+	for _, parent := range syms {
+		for _, child := range parent.Children {
+			if child.Children != nil {
+				t.Errorf("expected %s.%s to have no children, got %+v", parent.Name, child.Name, child.Children)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes godoc-style Markdown for the exported API: a heading
+// per package in first-seen order, then its structs with their exported
+// fields, embedded types, and methods, its interfaces with their method signatures,
+// and its functions, each with its doc comment. Signatures come from
+// stubSignature, so they carry parameter and result types but not field
+// types, which are not extracted. Methods declared on an alias are listed
+// under the struct it names.
+func WriteMarkdown(w io.Writer, result *ExtractResult) error {
+	aliases := newAliasTargets(result)
+	var pkgs []string
+	seen := make(map[string]bool)
+	addPkg := func(pkg string) {
+		if !seen[pkg] {
+			seen[pkg] = true
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	funcs := make(map[string][]FunctionInfo)
+	methods := make(map[string]map[string][]FunctionInfo)
+	for _, fn := range result.Functions {
+		if !fn.Exported || fn.IsFuncLit {
+			continue
+		}
+		if fn.Receiver == "" {
+			addPkg(fn.Package)
+			funcs[fn.Package] = append(funcs[fn.Package], fn)
+			continue
+		}
+		if methods[fn.Package] == nil {
+			methods[fn.Package] = make(map[string][]FunctionInfo)
+		}
+		recv := aliases.receiverOf(fn).name
+		methods[fn.Package][recv] = append(methods[fn.Package][recv], fn)
+	}
+	structs := make(map[string][]StructInfo)
+	for _, s := range result.Structs {
+		if s.Exported {
+			addPkg(s.Package)
+			structs[s.Package] = append(structs[s.Package], s)
+		}
+	}
+	ifaces := make(map[string][]InterfaceInfo)
+	for _, iface := range result.Interfaces {
+		if isExported(iface.Name) {
+			addPkg(iface.Package)
+			ifaces[iface.Package] = append(ifaces[iface.Package], iface)
+		}
+	}
+
+	var b strings.Builder
+	for i, pkg := range pkgs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# Package %s\n", pkg)
+		if len(structs[pkg]) > 0 || len(ifaces[pkg]) > 0 {
+			b.WriteString("\n## Types\n")
+		}
+		for _, s := range structs[pkg] {
+			fmt.Fprintf(&b, "\n### type %s\n", s.Name)
+			writeMarkdownDoc(&b, s.Doc)
+			fields := keep(s.Fields, isExported)
+			if len(fields) > 0 || len(s.Embedded) > 0 {
+				b.WriteString("\nFields:\n\n")
+				for _, e := range s.Embedded {
+					fmt.Fprintf(&b, "- `%s` (embedded)\n", e)
+				}
+				for _, f := range fields {
+					fmt.Fprintf(&b, "- `%s`\n", f)
+				}
+			}
+			for _, m := range methods[pkg][s.Name] {
+				fmt.Fprintf(&b, "\n#### func (%s) %s\n", s.Name, m.Name)
+				writeMarkdownFunc(&b, m)
+			}
+		}
+		for _, iface := range ifaces[pkg] {
+			fmt.Fprintf(&b, "\n### type %s interface\n", iface.Name)
+			writeMarkdownDoc(&b, iface.Doc)
+			if len(iface.Signatures) > 0 {
+				fmt.Fprintf(&b, "\n```go\ntype %s interface {\n", iface.Name)
+				for _, sig := range iface.Signatures {
+					fmt.Fprintf(&b, "\t%s\n", sig)
+				}
+				b.WriteString("}\n```\n")
+			}
+		}
+		if len(funcs[pkg]) > 0 {
+			b.WriteString("\n## Functions\n")
+		}
+		for _, fn := range funcs[pkg] {
+			fmt.Fprintf(&b, "\n### func %s\n", fn.Name)
+			writeMarkdownFunc(&b, fn)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownFunc writes fn's signature as a Go code block followed by its
+// doc comment.
+func writeMarkdownFunc(b *strings.Builder, fn FunctionInfo) {
+	fmt.Fprintf(b, "\n```go\n%s\n```\n", stubSignature(fn))
+	writeMarkdownDoc(b, fn.Doc)
+}
+
+// writeMarkdownDoc writes a doc comment as a paragraph, if there is one.
+func writeMarkdownDoc(b *strings.Builder, doc string) {
+	if doc = strings.TrimSpace(doc); doc != "" {
+		fmt.Fprintf(b, "\n%s\n", doc)
+	}
+}
@@ -0,0 +1,64 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "server.go")
+	os.WriteFile(src, []byte(`package server
+
+// Server serves requests.
+type Server struct {
+	Config
+	Addr string
+	conn any
+}
+
+// Start starts s.
+func (s *Server) Start(ctx context.Context) error { return nil }
+
+func (s *Server) stop() {}
+
+// Handler handles one request.
+type Handler interface {
+	Handle(req string) (int, error)
+}
+
+// New returns a Server listening on addr.
+func New(addr string) *Server { return nil }
+
+func helper() {}
+
+type internal struct{}
+`), 0644)
+
+	result := ExtractFiles([]string{src}, Options{})
+	var b strings.Builder
+	if err := WriteMarkdown(&b, result); err != nil {
+		t.Fatalf("writeMarkdown failed: %v", err)
+	}
+
+	expected := "# Package server\n" +
+		"\n## Types\n" +
+		"\n### type Server\n" +
+		"\nServer serves requests.\n" +
+		"\nFields:\n\n- `Config` (embedded)\n- `Addr`\n" +
+		"\n#### func (Server) Start\n" +
+		"\n```go\nfunc (*Server) Start(ctx context.Context) error\n```\n" +
+		"\nStart starts s.\n" +
+		"\n### type Handler interface\n" +
+		"\nHandler handles one request.\n" +
+		"\n```go\ntype Handler interface {\n\tHandle(string) (int, error)\n}\n```\n" +
+		"\n## Functions\n" +
+		"\n### func New\n" +
+		"\n```go\nfunc New(addr string) *Server\n```\n" +
+		"\nNew returns a Server listening on addr.\n"
+	if got := b.String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,850 @@
+package extract
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fileScope holds per-file information that function-level analyses need to
+// resolve identifiers without full type checking.
+type fileScope struct {
+	// imports maps each import's local name to its import path.
+	imports map[string]string
+	// dotImports holds the paths of packages imported with import . "path",
+	// whose exported names are used unqualified.
+	dotImports []string
+	// funcs holds the names of top-level (non-method) functions in the file.
+	funcs map[string]bool
+	// types holds the names of top-level types declared in the file.
+	types map[string]bool
+	// consts holds the names of top-level constants declared in the file.
+	consts map[string]bool
+	// comments holds every comment group in the file.
+	comments []*ast.CommentGroup
+}
+
+// newFileScope collects the import names, top-level function, type, and
+// constant names, and comments of file.
+func newFileScope(file *ast.File) *fileScope {
+	scope := &fileScope{
+		imports:  make(map[string]string),
+		funcs:    make(map[string]bool),
+		types:    make(map[string]bool),
+		consts:   make(map[string]bool),
+		comments: file.Comments,
+	}
+	for _, imp := range file.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := importName(path)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		if name == "." {
+			scope.dotImports = append(scope.dotImports, path)
+			continue
+		}
+		if name == "_" {
+			continue
+		}
+		scope.imports[name] = path
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				scope.funcs[d.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					scope.types[spec.Name.Name] = true
+				case *ast.ValueSpec:
+					if d.Tok == token.CONST {
+						for _, name := range spec.Names {
+							scope.consts[name.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return scope
+}
+
+// importName guesses the package name of an unaliased import from its path:
+// the last path element, skipping a trailing major-version element like /v2.
+func importName(path string) string {
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && isMajorVersion(name) {
+		name = parts[len(parts)-2]
+	}
+	return name
+}
+
+// isMajorVersion reports whether s looks like a module major-version suffix (v2, v3, ...).
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+	return err == nil
+}
+
+// dotImported reports whether the bare identifier name may come from a
+// dot-imported package: the file has a dot import and name is exported but
+// not declared at the top level of the file. Without type checking, an
+// exported function from another file of the same package is
+// indistinguishable and counts too.
+func (s *fileScope) dotImported(name string) bool {
+	return len(s.dotImports) > 0 && isExported(name) && !s.funcs[name] && !s.types[name]
+}
+
+// countCalls classifies the calls in body. A call is external when the callee
+// is a selector on an imported package (fmt.Println), resolving renamed
+// imports, or a bare exported identifier in a file with a dot import
+// (Println after import . "fmt"). It is internal when it is a bare identifier
+// naming a top-level function declared in the same file. Method calls,
+// builtins, and calls through variables count as neither.
+func countCalls(body *ast.BlockStmt, scope *fileScope) (external, internal int) {
+	if body == nil {
+		return 0, 0
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			if scope.funcs[fun.Name] {
+				internal++
+			} else if scope.dotImported(fun.Name) {
+				external++
+			}
+		case *ast.SelectorExpr:
+			if x, ok := fun.X.(*ast.Ident); ok {
+				if _, imported := scope.imports[x.Name]; imported {
+					external++
+				}
+			}
+		}
+		return true
+	})
+	return external, internal
+}
+
+// calledMethods returns the sorted, distinct names of the methods called in
+// body: selector calls like mu.Lock() or b.WriteString(s) whose receiver is
+// not an imported package. Calls of function-typed struct fields look the
+// same and are included. Bare and package-qualified function calls are not;
+// they are listed in Calls.
+func calledMethods(body *ast.BlockStmt, scope *fileScope) []string {
+	methods := []string{}
+	if body == nil {
+		return methods
+	}
+	seen := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if _, imported := scope.imports[x.Name]; imported {
+				return true
+			}
+		}
+		if !seen[sel.Sel.Name] {
+			seen[sel.Sel.Name] = true
+			methods = append(methods, sel.Sel.Name)
+		}
+		return true
+	})
+	sort.Strings(methods)
+	return methods
+}
+
+// lockCalls returns the sorted, distinct receiver expressions body calls
+// Lock or RLock on (acquired) and Unlock or RUnlock on (released), as
+// written: mu, s.mu, c.cache.lock. It is a heuristic over selector calls
+// without type checking, so any type with methods of those names counts,
+// read and write locks are not told apart, and a lock taken in one function
+// and released in another shows up unbalanced in both. Deferred calls count
+// like any other.
+func lockCalls(body *ast.BlockStmt) (acquired, released []string) {
+	acquired, released = []string{}, []string{}
+	if body == nil {
+		return acquired, released
+	}
+	seenAcquired, seenReleased := make(map[string]bool), make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		recv := types.ExprString(sel.X)
+		switch sel.Sel.Name {
+		case "Lock", "RLock":
+			if !seenAcquired[recv] {
+				seenAcquired[recv] = true
+				acquired = append(acquired, recv)
+			}
+		case "Unlock", "RUnlock":
+			if !seenReleased[recv] {
+				seenReleased[recv] = true
+				released = append(released, recv)
+			}
+		}
+		return true
+	})
+	sort.Strings(acquired)
+	sort.Strings(released)
+	return acquired, released
+}
+
+// countLabels reports whether body contains a goto statement and counts its
+// labeled statements (the targets of goto and labeled break/continue).
+func countLabels(body *ast.BlockStmt) (hasGoto bool, labeled int) {
+	if body == nil {
+		return false, 0
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.BranchStmt:
+			if node.Tok == token.GOTO {
+				hasGoto = true
+			}
+		case *ast.LabeledStmt:
+			labeled++
+		}
+		return true
+	})
+	return hasGoto, labeled
+}
+
+// neverReturns is a best-effort heuristic for functions that never return to
+// their caller: the body contains no return statement and its last statement
+// is an infinite for loop without a break out of it, an empty select, or a
+// call to panic, os.Exit, or log.Fatal*/log.Panic*, possibly inside an
+// if/else whose branches all end that way. It does not follow calls, switch
+// statements, or goto, so it can miss terminating functions.
+func neverReturns(body *ast.BlockStmt, scope *fileScope) bool {
+	if body == nil || len(body.List) == 0 || containsReturn(body) {
+		return false
+	}
+	return terminates(body.List[len(body.List)-1], "", scope)
+}
+
+// terminates reports whether stmt never completes normally, per the
+// neverReturns heuristic. label is the label attached to stmt, if any.
+func terminates(stmt ast.Stmt, label string, scope *fileScope) bool {
+	switch s := stmt.(type) {
+	case *ast.LabeledStmt:
+		return terminates(s.Stmt, s.Label.Name, scope)
+	case *ast.ForStmt:
+		return s.Cond == nil && !breaksOut(s.Body, label)
+	case *ast.SelectStmt:
+		return len(s.Body.List) == 0
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		return ok && isExitCall(call, scope)
+	case *ast.BlockStmt:
+		return len(s.List) > 0 && terminates(s.List[len(s.List)-1], "", scope)
+	case *ast.IfStmt:
+		return s.Else != nil && terminates(s.Body, "", scope) && terminates(s.Else, "", scope)
+	}
+	return false
+}
+
+// breaksOut reports whether body contains a break that leaves the loop owning
+// it: an unlabeled break not nested in another breakable statement, or a
+// break naming label.
+func breaksOut(body *ast.BlockStmt, label string) bool {
+	found := false
+	var visit func(root ast.Node, nested bool)
+	visit = func(root ast.Node, nested bool) {
+		ast.Inspect(root, func(n ast.Node) bool {
+			if found {
+				return false
+			}
+			switch node := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+				if node != root {
+					visit(node, true)
+					return false
+				}
+			case *ast.BranchStmt:
+				if node.Tok == token.BREAK {
+					if node.Label == nil {
+						found = !nested
+					} else {
+						found = label != "" && node.Label.Name == label
+					}
+				}
+			}
+			return true
+		})
+	}
+	visit(body, false)
+	return found
+}
+
+// containsReturn reports whether body has a return statement outside of any
+// nested function literal.
+func containsReturn(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isExitCall reports whether call is panic(...), os.Exit, or one of the
+// log.Fatal and log.Panic functions, resolving import aliases and dot imports
+// via scope.
+func isExitCall(call *ast.CallExpr, scope *fileScope) bool {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		if fun.Name == "panic" {
+			return true
+		}
+		if scope.dotImported(fun.Name) {
+			for _, path := range scope.dotImports {
+				if isExitFunc(path, fun.Name) {
+					return true
+				}
+			}
+		}
+	case *ast.SelectorExpr:
+		if x, ok := fun.X.(*ast.Ident); ok {
+			return isExitFunc(scope.imports[x.Name], fun.Sel.Name)
+		}
+	}
+	return false
+}
+
+// isExitFunc reports whether the function name of the package at path never
+// returns: os.Exit and the log.Fatal and log.Panic functions.
+func isExitFunc(path, name string) bool {
+	switch path {
+	case "os":
+		return name == "Exit"
+	case "log":
+		return strings.HasPrefix(name, "Fatal") || strings.HasPrefix(name, "Panic")
+	}
+	return false
+}
+
+// predeclaredTypes are the builtin type names, which referencedTypes leaves
+// out, plus nil for type switch cases.
+var predeclaredTypes = map[string]bool{
+	"nil": true,
+	"any": true, "bool": true, "byte": true, "comparable": true,
+	"complex64": true, "complex128": true, "error": true,
+	"float32": true, "float64": true, "int": true, "int8": true,
+	"int16": true, "int32": true, "int64": true, "rune": true,
+	"string": true, "uint": true, "uint8": true, "uint16": true,
+	"uint32": true, "uint64": true, "uintptr": true,
+}
+
+// referencedTypes returns the sorted, distinct type names fn mentions in its
+// parameters, results, and body: composite literal types, declared variable
+// and local types, type assertions and type switch cases, make/new arguments,
+// function literal signatures, and conversions. Without type checking a
+// conversion is only recognised when its type is a composite expression
+// ([]byte(s)) or a type declared in the same file; qualified names keep their
+// package (time.Duration). Predeclared types and the type parameters of fn
+// or its receiver are left out.
+func referencedTypes(fn *ast.FuncDecl, scope *fileScope) []string {
+	typeParams := make(map[string]bool)
+	for _, field := range fieldsOf(fn.Type.TypeParams) {
+		for _, name := range field.Names {
+			typeParams[name.Name] = true
+		}
+	}
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		var indices []ast.Expr
+		switch r := recv.(type) {
+		case *ast.IndexExpr:
+			indices = []ast.Expr{r.Index}
+		case *ast.IndexListExpr:
+			indices = r.Indices
+		}
+		for _, index := range indices {
+			if id, ok := index.(*ast.Ident); ok {
+				typeParams[id.Name] = true
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !predeclaredTypes[name] && !typeParams[name] {
+			seen[name] = true
+		}
+	}
+	collectTypeNames(fn.Type.Params, add)
+	collectTypeNames(fn.Type.Results, add)
+	if fn.Body != nil {
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CompositeLit:
+				collectTypeNames(node.Type, add)
+			case *ast.ValueSpec:
+				collectTypeNames(node.Type, add)
+			case *ast.TypeSpec:
+				collectTypeNames(node.Type, add)
+			case *ast.TypeAssertExpr:
+				collectTypeNames(node.Type, add)
+			case *ast.FuncLit:
+				collectTypeNames(node.Type, add)
+			case *ast.TypeSwitchStmt:
+				for _, stmt := range node.Body.List {
+					for _, t := range stmt.(*ast.CaseClause).List {
+						collectTypeNames(t, add)
+					}
+				}
+			case *ast.CallExpr:
+				switch fun := node.Fun.(type) {
+				case *ast.Ident:
+					if (fun.Name == "make" || fun.Name == "new") && len(node.Args) > 0 {
+						collectTypeNames(node.Args[0], add)
+					} else if scope.types[fun.Name] {
+						add(fun.Name)
+					}
+				case *ast.ArrayType, *ast.MapType, *ast.ChanType, *ast.FuncType, *ast.InterfaceType, *ast.ParenExpr:
+					collectTypeNames(fun, add)
+				}
+			}
+			return true
+		})
+	}
+
+	types := make([]string, 0, len(seen))
+	for name := range seen {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// fieldsOf returns the fields of list, which may be nil.
+func fieldsOf(list *ast.FieldList) []*ast.Field {
+	if list == nil {
+		return nil
+	}
+	return list.List
+}
+
+// collectTypeNames calls add for each type name in the type expression node,
+// which may also be a field list. Field and parameter names, array lengths,
+// and a nil node are skipped.
+func collectTypeNames(node ast.Node, add func(string)) {
+	switch t := node.(type) {
+	case *ast.Ident:
+		add(t.Name)
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			add(x.Name + "." + t.Sel.Name)
+		}
+	case *ast.StarExpr:
+		collectTypeNames(t.X, add)
+	case *ast.ParenExpr:
+		collectTypeNames(t.X, add)
+	case *ast.ArrayType:
+		collectTypeNames(t.Elt, add)
+	case *ast.Ellipsis:
+		collectTypeNames(t.Elt, add)
+	case *ast.MapType:
+		collectTypeNames(t.Key, add)
+		collectTypeNames(t.Value, add)
+	case *ast.ChanType:
+		collectTypeNames(t.Value, add)
+	case *ast.IndexExpr:
+		collectTypeNames(t.X, add)
+		collectTypeNames(t.Index, add)
+	case *ast.IndexListExpr:
+		collectTypeNames(t.X, add)
+		for _, index := range t.Indices {
+			collectTypeNames(index, add)
+		}
+	case *ast.FuncType:
+		collectTypeNames(t.Params, add)
+		collectTypeNames(t.Results, add)
+	case *ast.StructType:
+		collectTypeNames(t.Fields, add)
+	case *ast.InterfaceType:
+		collectTypeNames(t.Methods, add)
+	case *ast.FieldList:
+		if t != nil {
+			for _, field := range t.List {
+				collectTypeNames(field.Type, add)
+			}
+		}
+	}
+}
+
+// builtinFuncs are the predeclared functions and constants, which together
+// with predeclaredTypes make up the identifiers shadowedBuiltins looks for.
+var builtinFuncs = map[string]bool{
+	"append": true, "cap": true, "clear": true, "close": true,
+	"complex": true, "copy": true, "delete": true, "imag": true,
+	"len": true, "make": true, "max": true, "min": true, "new": true,
+	"panic": true, "print": true, "println": true, "real": true,
+	"recover": true, "true": true, "false": true, "iota": true,
+}
+
+// shadowedBuiltins returns the sorted, distinct predeclared identifiers
+// (builtin functions, types, constants, and nil) that fn redeclares anywhere
+// walkDecls looks.
+func shadowedBuiltins(fn *ast.FuncDecl) []string {
+	seen := make(map[string]bool)
+	walkDecls(fn, func(name *ast.Ident) {
+		if builtinFuncs[name.Name] || predeclaredTypes[name.Name] {
+			seen[name.Name] = true
+		}
+	})
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// walkDecls calls declare for every name fn declares: its receiver,
+// parameters, and named results, and inside its body the parameters and
+// results of function literals, := and range variables, and local var,
+// const, and type declarations.
+func walkDecls(fn *ast.FuncDecl, declare func(*ast.Ident)) {
+	declareFields := func(list *ast.FieldList) {
+		for _, field := range fieldsOf(list) {
+			for _, name := range field.Names {
+				declare(name)
+			}
+		}
+	}
+	declareFields(fn.Recv)
+	declareFields(fn.Type.Params)
+	declareFields(fn.Type.Results)
+	if fn.Body == nil {
+		return
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			declareFields(node.Type.Params)
+			declareFields(node.Type.Results)
+		case *ast.AssignStmt:
+			if node.Tok == token.DEFINE {
+				for _, lhs := range node.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok {
+						declare(id)
+					}
+				}
+			}
+		case *ast.RangeStmt:
+			if node.Tok == token.DEFINE {
+				for _, x := range []ast.Expr{node.Key, node.Value} {
+					if id, ok := x.(*ast.Ident); ok {
+						declare(id)
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for _, name := range node.Names {
+				declare(name)
+			}
+		case *ast.TypeSpec:
+			declare(node.Name)
+		}
+		return true
+	})
+}
+
+// isAccessor reports whether fn is a trivial getter or setter method: its
+// body is a single statement that either returns a field of the receiver
+// (return s.name) or assigns a parameter to one (s.name = name). Methods with
+// unnamed receivers and plain functions are never accessors.
+func isAccessor(fn *ast.FuncDecl) bool {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 || len(fn.Recv.List[0].Names) == 0 || fn.Body == nil || len(fn.Body.List) != 1 {
+		return false
+	}
+	recv := fn.Recv.List[0].Names[0].Name
+	onReceiver := func(expr ast.Expr) bool {
+		sel, ok := expr.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		x, ok := sel.X.(*ast.Ident)
+		return ok && x.Name == recv && recv != "_"
+	}
+
+	switch stmt := fn.Body.List[0].(type) {
+	case *ast.ReturnStmt:
+		return len(stmt.Results) == 1 && onReceiver(stmt.Results[0])
+	case *ast.AssignStmt:
+		if stmt.Tok != token.ASSIGN || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 || !onReceiver(stmt.Lhs[0]) {
+			return false
+		}
+		param, ok := stmt.Rhs[0].(*ast.Ident)
+		if !ok {
+			return false
+		}
+		for _, field := range fieldsOf(fn.Type.Params) {
+			for _, name := range field.Names {
+				if name.Name == param.Name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// usesReflection reports whether body refers to the reflect or unsafe
+// package through a selector (reflect.TypeOf, unsafe.Pointer), resolving
+// renamed imports via scope. When either package is dot-imported, a call of
+// an unqualified exported name it could provide (TypeOf(v)) counts too.
+func usesReflection(body *ast.BlockStmt, scope *fileScope) bool {
+	if body == nil {
+		return false
+	}
+	dotReflect := false
+	for _, path := range scope.dotImports {
+		if path == "reflect" || path == "unsafe" {
+			dotReflect = true
+		}
+	}
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.SelectorExpr:
+			if x, ok := node.X.(*ast.Ident); ok {
+				if path := scope.imports[x.Name]; path == "reflect" || path == "unsafe" {
+					found = true
+				}
+			}
+		case *ast.CallExpr:
+			if fun, ok := node.Fun.(*ast.Ident); ok && dotReflect && scope.dotImported(fun.Name) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// cyclomatic returns the cyclomatic complexity of body: 1 plus one for each
+// if, for, and range statement, each non-default case or select clause, and
+// each && and || operator. Function literals count toward the enclosing
+// function.
+func cyclomatic(body *ast.BlockStmt) int {
+	complexity := 1
+	if body == nil {
+		return complexity
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if node.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if node.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if node.Op == token.LAND || node.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// constantReturn returns the source text of the value fn always returns
+// when its body is a single return of one constant: a basic literal,
+// optionally negated (-1), true, false, nil, or a constant declared at the top
+// level of the same file. Anything else, including constants from other
+// files or packages, yields "".
+func constantReturn(fset *token.FileSet, fn *ast.FuncDecl, src string, scope *fileScope) string {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return ""
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return ""
+	}
+	expr := ret.Results[0]
+	if unary, ok := expr.(*ast.UnaryExpr); ok && (unary.Op == token.SUB || unary.Op == token.ADD) {
+		if _, ok := unary.X.(*ast.BasicLit); ok {
+			return nodeText(fset, expr, src)
+		}
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return nodeText(fset, e, src)
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" || e.Name == "nil" || scope.consts[e.Name] {
+			return e.Name
+		}
+	}
+	return ""
+}
+
+// countCases counts the case clauses, default included, of the expression
+// and type switches in body and the comm clauses of its selects. Function
+// literals count toward the enclosing function.
+func countCases(body *ast.BlockStmt) (switchCases, selectCases int) {
+	if body == nil {
+		return 0, 0
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.CaseClause:
+			switchCases++
+		case *ast.CommClause:
+			selectCases++
+		}
+		return true
+	})
+	return switchCases, selectCases
+}
+
+// countAssignments counts the assignment statements in body: plain and
+// compound assignments (x = 1, x += 1) as well as short variable
+// declarations (x := 1). Increments, var declarations, and range clauses are
+// not assignment statements and don't count. Function literals count toward
+// the enclosing function.
+func countAssignments(body *ast.BlockStmt) int {
+	if body == nil {
+		return 0
+	}
+	count := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.AssignStmt); ok {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// countMagicNumbers counts the integer and float literals in body that are
+// likely to want a name. Excluded are literals whose value is 0 or 1 (so 0,
+// 1.0, 0x1, and -1, whose literal is 1), and literals inside local const
+// declarations, which already name them.
+func countMagicNumbers(body *ast.BlockStmt) int {
+	if body == nil {
+		return 0
+	}
+	zero, one := constant.MakeInt64(0), constant.MakeInt64(1)
+	count := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.GenDecl:
+			return node.Tok != token.CONST
+		case *ast.BasicLit:
+			if node.Kind != token.INT && node.Kind != token.FLOAT {
+				return true
+			}
+			v := constant.MakeFromLiteral(node.Value, node.Kind, 0)
+			if v.Kind() == constant.Unknown || constant.Compare(v, token.EQL, zero) || constant.Compare(v, token.EQL, one) {
+				return true
+			}
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// isPlaceholderBody reports whether body is a stub: it has no statements, or
+// its only statement is a panic with a string literal mentioning that the
+// code is unimplemented or a TODO (panic("not implemented")). A nil body is
+// not a placeholder; see HasNoBody.
+func isPlaceholderBody(body *ast.BlockStmt) bool {
+	if body == nil {
+		return false
+	}
+	if len(body.List) == 0 {
+		return true
+	}
+	if len(body.List) != 1 {
+		return false
+	}
+	stmt, ok := body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return false
+	}
+	if fun, ok := call.Fun.(*ast.Ident); !ok || fun.Name != "panic" {
+		return false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return false
+	}
+	msg := strings.ToLower(lit.Value)
+	return strings.Contains(msg, "implement") || strings.Contains(msg, "todo")
+}
+
+// commentDensity returns the fraction of the source lines spanned by fn that
+// hold a comment, counting each line once however many comments it has. The
+// doc comment lies before fn and does not count.
+func commentDensity(fset *token.FileSet, fn *ast.FuncDecl, scope *fileScope) float64 {
+	start, end := fset.Position(fn.Pos()).Line, fset.Position(fn.End()).Line
+	lines := make(map[int]bool)
+	for _, group := range scope.comments {
+		if group.Pos() < fn.Pos() || group.End() > fn.End() {
+			continue
+		}
+		for _, c := range group.List {
+			for line := fset.Position(c.Pos()).Line; line <= fset.Position(c.End()).Line; line++ {
+				lines[line] = true
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return 0
+	}
+	return float64(len(lines)) / float64(end-start+1)
+}
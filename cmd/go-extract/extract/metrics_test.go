@@ -0,0 +1,695 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountCalls(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "calls.go")
+	os.WriteFile(src, []byte(`package main
+
+import (
+	"fmt"
+	str "strings"
+	"github.com/example/mod/v2"
+)
+
+func helper() string { return "" }
+
+func Run(s *Server) {
+	fmt.Println(helper())
+	str.ToUpper(helper())
+	mod.Do()
+	s.Start()
+	_ = len("x")
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	run := result.Functions[1]
+	if run.ExternalCalls != 3 {
+		t.Errorf("expected 3 external calls, got %d", run.ExternalCalls)
+	}
+	if run.InternalCalls != 2 {
+		t.Errorf("expected 2 internal calls, got %d", run.InternalCalls)
+	}
+}
+
+func TestCountCallsAliasedAndDotImports(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "dot.go")
+	os.WriteFile(src, []byte(`package main
+
+import (
+	. "fmt"
+	f "fmt"
+	. "reflect"
+	. "os"
+)
+
+type Celsius float64
+
+func Local() {}
+
+func Quit() { Exit(1) }
+
+func Run(v any) {
+	Println(f.Sprint(v))
+	_ = TypeOf(v)
+	_ = Celsius(1)
+	Local()
+	v.(interface{ Close() }).Close()
+}
+
+func Plain() { Local() }
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	quit, run, plain := result.Functions[1], result.Functions[2], result.Functions[3]
+	if !quit.NeverReturns {
+		t.Errorf("expected Quit to never return via the dot-imported os.Exit")
+	}
+	if run.ExternalCalls != 3 || run.InternalCalls != 1 {
+		t.Errorf("expected 3 external and 1 internal calls, got %d and %d", run.ExternalCalls, run.InternalCalls)
+	}
+	if !run.UsesReflection || plain.UsesReflection {
+		t.Errorf("expected only Run to use reflection via the dot import, got %v and %v", run.UsesReflection, plain.UsesReflection)
+	}
+	if names := result.Imports[0].Name + "," + result.Imports[1].Name; names != ".,f" {
+		t.Errorf("expected import names .,f, got %s", names)
+	}
+}
+
+func TestCalledMethods(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "methods.go")
+	os.WriteFile(src, []byte(`package main
+
+import (
+	"strings"
+	"sync"
+)
+
+func Write(mu *sync.Mutex, b *strings.Builder, s *Server) {
+	mu.Lock()
+	defer mu.Unlock()
+	b.WriteString(strings.ToUpper("x"))
+	s.conn.Close()
+	s.handler()
+	mu.Lock()
+	helper()
+}
+
+func helper() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := "Close,Lock,Unlock,WriteString,handler"
+	if got := strings.Join(result.Functions[0].CalledMethods, ","); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if got := result.Functions[1].CalledMethods; got == nil || len(got) != 0 {
+		t.Errorf("expected no called methods for helper, got %v", got)
+	}
+}
+
+func TestLockCalls(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "locks.go")
+	os.WriteFile(src, []byte(`package main
+
+func (s *Store) Get(k string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.items[k]
+}
+
+func (s *Store) Leak() {
+	s.mu.Lock()
+	mu.Lock()
+	mu.Unlock()
+	s.lock(true)
+}
+
+func Plain() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := map[string][2]string{
+		"Get":   {"s.mu", "s.mu"},
+		"Leak":  {"mu,s.mu", "mu"},
+		"Plain": {"", ""},
+	}
+	for _, fn := range result.Functions {
+		want := expected[fn.Name]
+		if got := strings.Join(fn.LocksAcquired, ","); got != want[0] {
+			t.Errorf("%s: expected acquired %q, got %q", fn.Name, want[0], got)
+		}
+		if got := strings.Join(fn.LocksReleased, ","); got != want[1] {
+			t.Errorf("%s: expected released %q, got %q", fn.Name, want[1], got)
+		}
+	}
+}
+
+func TestCountLabels(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "labels.go")
+	os.WriteFile(src, []byte(`package main
+
+func Retry() {
+retry:
+	if fail() {
+		goto retry
+	}
+}
+
+func Scan(rows [][]int) {
+outer:
+	for _, row := range rows {
+		for _, v := range row {
+			if v < 0 {
+				continue outer
+			}
+		}
+	}
+}
+
+func Plain() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	retry, scan, plain := result.Functions[0], result.Functions[1], result.Functions[2]
+	if !retry.HasGoto || retry.LabeledStmts != 1 {
+		t.Errorf("expected Retry to use goto with 1 label, got %v/%d", retry.HasGoto, retry.LabeledStmts)
+	}
+	if scan.HasGoto || scan.LabeledStmts != 1 {
+		t.Errorf("expected Scan to have 1 label and no goto, got %v/%d", scan.HasGoto, scan.LabeledStmts)
+	}
+	if plain.HasGoto || plain.LabeledStmts != 0 {
+		t.Errorf("expected Plain to have no labels")
+	}
+}
+
+func TestNeverReturns(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "exit.go")
+	os.WriteFile(src, []byte(`package main
+
+import (
+	"log"
+	sys "os"
+)
+
+func Serve() {
+	for {
+		select {
+		case <-done:
+			break
+		}
+	}
+}
+
+func Die(err error) {
+	if err != nil {
+		log.Fatalf("fatal: %v", err)
+	} else {
+		sys.Exit(1)
+	}
+}
+
+func Block() { select {} }
+
+func Loop() {
+	for {
+		if ready() {
+			break
+		}
+	}
+}
+
+func Early(ok bool) {
+	if ok {
+		return
+	}
+	panic("not ok")
+}
+
+func Maybe(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := map[string]bool{
+		"Serve": true,
+		"Die":   true,
+		"Block": true,
+		"Loop":  false,
+		"Early": false,
+		"Maybe": false,
+	}
+	for _, fn := range result.Functions {
+		if fn.NeverReturns != expected[fn.Name] {
+			t.Errorf("%s: expected never_returns %v, got %v", fn.Name, expected[fn.Name], fn.NeverReturns)
+		}
+	}
+}
+
+func TestConstantReturn(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "consts.go")
+	os.WriteFile(src, []byte(`package main
+
+import "net/http"
+
+func Name() string { return "server" }
+
+func Limit() int { return -1 }
+
+func Enabled() bool { return true }
+
+func Mode() Mode { return ModeFast }
+
+func Status() int { return http.StatusOK }
+
+func Var() int { return counter }
+
+func Sum() int { return 1 + 2 }
+
+func Pair() (int, error) { return 0, nil }
+
+func Logged() int {
+	log()
+	return 1
+}
+
+const ModeFast Mode = 1
+
+var counter int
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := map[string]string{
+		"Name":    `"server"`,
+		"Limit":   "-1",
+		"Enabled": "true",
+		"Mode":    "ModeFast",
+	}
+	for _, fn := range result.Functions {
+		if fn.ConstantReturn != expected[fn.Name] {
+			t.Errorf("%s: expected constant return %q, got %q", fn.Name, expected[fn.Name], fn.ConstantReturn)
+		}
+	}
+}
+
+func TestReferencedTypes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "types.go")
+	os.WriteFile(src, []byte(`package main
+
+import "time"
+
+type Celsius float64
+
+type Map[K comparable, V any] struct{}
+
+func Convert(cfg *Config, opts ...Option) (Result, error) {
+	var d time.Duration
+	items := []Item{{}}
+	m := make(map[string]*Entry)
+	c := Celsius(1.5)
+	b := []byte("x")
+	if r, ok := cfg.Reader.(io.Reader); ok {
+		_ = r
+	}
+	switch cfg.Value.(type) {
+	case Point, nil:
+	}
+	run(func(e Event) {})
+	return Result{}, nil
+}
+
+func (m *Map[K, V]) Get(k K) V {
+	var zero V
+	return zero
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := []string{"Celsius", "Config", "Entry", "Event", "Item", "Option", "Point", "Result", "io.Reader", "time.Duration"}
+	got := result.Functions[0].ReferencedTypes
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if get := result.Functions[1].ReferencedTypes; get == nil || len(get) != 0 {
+		t.Errorf("expected no referenced types for Get, got %v", get)
+	}
+}
+
+func TestShadowedBuiltins(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "shadow.go")
+	os.WriteFile(src, []byte(`package main
+
+func Shadow(len int, items []string) (error string) {
+	new := 1
+	for copy, cap := range items {
+		_, _ = copy, cap
+	}
+	var string = "x"
+	type any int
+	run(func(max int) {})
+	_, _ = new, string
+	return
+}
+
+func Clean(n int) int {
+	x := len("a")
+	return x + n
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := []string{"any", "cap", "copy", "error", "len", "max", "new", "string"}
+	if got := result.Functions[0].ShadowsBuiltin; strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := result.Functions[1].ShadowsBuiltin; got == nil || len(got) != 0 {
+		t.Errorf("expected no shadowed builtins for Clean, got %v", got)
+	}
+}
+
+func TestIsAccessor(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "accessors.go")
+	os.WriteFile(src, []byte(`package main
+
+func (s *Server) Name() string { return s.name }
+
+func (s *Server) SetName(name string) { s.name = name }
+
+func (s *Server) Addr() string { return s.host + ":" + s.port }
+
+func (s *Server) Reset() { s.name = "" }
+
+func (*Server) Kind() string { return "server" }
+
+func (s *Server) Count() int {
+	s.hits++
+	return s.count
+}
+
+func name(s *Server) string { return s.name }
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := map[string]bool{"Name": true, "SetName": true}
+	for _, fn := range result.Functions {
+		if fn.IsAccessor != expected[fn.Name] {
+			t.Errorf("%s: expected is_accessor %v, got %v", fn.Name, expected[fn.Name], fn.IsAccessor)
+		}
+	}
+}
+
+func TestUsesReflection(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "reflect.go")
+	os.WriteFile(src, []byte(`package main
+
+import (
+	r "reflect"
+	"unsafe"
+)
+
+func Kind(v any) string { return r.TypeOf(v).Kind().String() }
+
+func Size() uintptr { return unsafe.Sizeof(0) }
+
+func Plain(reflect int) int { return reflect.Value }
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	expected := map[string]bool{"Kind": true, "Size": true, "Plain": false}
+	for _, fn := range result.Functions {
+		if fn.UsesReflection != expected[fn.Name] {
+			t.Errorf("%s: expected uses_reflection %v, got %v", fn.Name, expected[fn.Name], fn.UsesReflection)
+		}
+	}
+}
+
+func TestCyclomatic(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "complexity.go")
+	os.WriteFile(src, []byte(`package main
+
+func Straight() {}
+
+func Branchy(xs []int, ch chan int) int {
+	total := 0
+	for _, x := range xs {
+		if x > 0 && x < 10 || x == 42 {
+			total += x
+		}
+	}
+	switch total {
+	case 1, 2:
+	case 3:
+	default:
+	}
+	select {
+	case v := <-ch:
+		total += v
+	default:
+	}
+	for i := 0; i < 3; i++ {
+	}
+	return total
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if got := result.Functions[0].Complexity; got != 1 {
+		t.Errorf("expected complexity 1 for Straight, got %d", got)
+	}
+	// 1 + range + if + && + || + 2 cases + 1 select case + for = 9
+	if got := result.Functions[1].Complexity; got != 9 {
+		t.Errorf("expected complexity 9 for Branchy, got %d", got)
+	}
+}
+
+func TestCountCases(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "cases.go")
+	os.WriteFile(src, []byte(`package main
+
+func Dispatch(v any, x int, ch chan int) {
+	switch v.(type) {
+	case int, uint:
+	case string:
+	default:
+	}
+	switch {
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	go func() {
+		switch x {
+		case 1:
+		}
+	}()
+}
+
+func Empty(x int) {
+	switch x {
+	}
+	select {}
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	dispatch, empty := result.Functions[0], result.Functions[1]
+	if dispatch.SwitchCases != 4 || dispatch.SelectCases != 2 {
+		t.Errorf("expected 4 switch and 2 select cases, got %d and %d", dispatch.SwitchCases, dispatch.SelectCases)
+	}
+	if empty.SwitchCases != 0 || empty.SelectCases != 0 {
+		t.Errorf("expected no cases for Empty, got %d and %d", empty.SwitchCases, empty.SelectCases)
+	}
+}
+
+func TestCountAssignments(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "assign.go")
+	os.WriteFile(src, []byte(`package main
+
+func Book(xs []int) int {
+	total := 0
+	var n int
+	for i, x := range xs {
+		total += x
+		n = i
+		n++
+	}
+	run(func() { total = 0 })
+	a, b := 1, 2
+	_, _ = a, b
+	return total + n
+}
+
+func Pure(x int) int { return x * 2 }
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if got := result.Functions[0].AssignCount; got != 6 {
+		t.Errorf("expected 6 assignments, got %d", got)
+	}
+	if got := result.Functions[1].AssignCount; got != 0 {
+		t.Errorf("expected no assignments for Pure, got %d", got)
+	}
+}
+
+func TestCountMagicNumbers(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "magic.go")
+	os.WriteFile(src, []byte(`package main
+
+func Retry() {
+	const attempts = 5
+	const (
+		base  = 100
+		ratio = 1.5
+	)
+	for i := 0; i < attempts; i++ {
+		sleep(i * 250)
+	}
+	x := -1 + 1.0 + 0x0 + 0.0
+	y := 3.14 * 42
+	_ = "99"
+	_, _ = x, y
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	// 250, 3.14, and 42.
+	if got := result.Functions[0].MagicNumbers; got != 3 {
+		t.Errorf("expected 3 magic numbers, got %d", got)
+	}
+}
+
+func TestPlaceholderBodies(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "stubs.go")
+	os.WriteFile(src, []byte(`package main
+
+func Empty() {}
+
+func Stub() error { panic("not implemented") }
+
+func Todo() { panic("TODO: wire up") }
+
+func Unreachable() { panic("unreachable") }
+
+func Real() { run() }
+
+//go:noescape
+func asmAdd(a, b int) int
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	empty := map[string]bool{"Empty": true, "Stub": true, "Todo": true}
+	for _, fn := range result.Functions {
+		if fn.IsEmpty != empty[fn.Name] {
+			t.Errorf("%s: expected is_empty %v, got %v", fn.Name, empty[fn.Name], fn.IsEmpty)
+		}
+		if fn.HasNoBody != (fn.Name == "asmAdd") {
+			t.Errorf("%s: unexpected has_no_body %v", fn.Name, fn.HasNoBody)
+		}
+	}
+}
+
+func TestCommentDensity(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "comments.go")
+	os.WriteFile(src, []byte(`package main
+
+// Commented has a doc comment that does not count.
+func Commented() {
+	// setup
+	x := 1 // trailing
+	/* block
+	   comment */
+	_ = x
+}
+
+func Bare() {
+	_ = 1
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	// Lines 5, 6, 7, and 8 of the 7-line function hold comments.
+	if got := result.Functions[0].CommentDensity; got != 4.0/7.0 {
+		t.Errorf("expected density 4/7, got %v", got)
+	}
+	if got := result.Functions[1].CommentDensity; got != 0 {
+		t.Errorf("expected density 0 for Bare, got %v", got)
+	}
+}
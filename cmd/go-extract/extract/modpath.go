@@ -0,0 +1,83 @@
+package extract
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// goModule is a module found by findModule: its path and root directory.
+type goModule struct {
+	path, dir string
+}
+
+// ResolveModules marks each import Internal when it belongs to the module
+// containing the importing file, found through the nearest go.mod above it.
+// Relative imports such as "./util" are rewritten to the full import path
+// they denote within that module. An internal import is never counted as
+// standard library, even when the module path has no dot in its first
+// element. ModulePath is set to the module of the first file that has one.
+// Files not on disk, such as archive entries, and files with no go.mod above
+// them are left as is.
+func ResolveModules(result *ExtractResult) {
+	cache := make(map[string]*goModule)
+	for i, imp := range result.Imports {
+		if _, err := os.Stat(imp.File); err != nil {
+			continue
+		}
+		dir, err := filepath.Abs(filepath.Dir(imp.File))
+		if err != nil {
+			continue
+		}
+		mod := findModule(dir, cache)
+		if mod == nil {
+			continue
+		}
+		if result.ModulePath == "" {
+			result.ModulePath = mod.path
+		}
+		if strings.HasPrefix(imp.Path, "./") || strings.HasPrefix(imp.Path, "../") {
+			rel, err := filepath.Rel(mod.dir, dir)
+			if err != nil {
+				continue
+			}
+			imp.Path = path.Join(mod.path, filepath.ToSlash(rel), imp.Path)
+		}
+		if imp.Path == mod.path || strings.HasPrefix(imp.Path, mod.path+"/") {
+			imp.Internal = true
+			imp.Std = false
+		}
+		result.Imports[i] = imp
+	}
+	collectDependencies(result)
+}
+
+// findModule returns the module whose go.mod is in dir or its nearest
+// ancestor, or nil if there is none. Lookups are memoized per directory in
+// cache.
+func findModule(dir string, cache map[string]*goModule) *goModule {
+	if mod, ok := cache[dir]; ok {
+		return mod
+	}
+	var mod *goModule
+	if modPath := readModulePath(filepath.Join(dir, "go.mod")); modPath != "" {
+		mod = &goModule{path: modPath, dir: dir}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		mod = findModule(parent, cache)
+	}
+	cache[dir] = mod
+	return mod
+}
+
+// readModulePath returns the module path declared by the go.mod file at
+// gomod, or "" if the file is missing or has no module directive.
+func readModulePath(gomod string) string {
+	data, err := os.ReadFile(gomod)
+	if err != nil {
+		return ""
+	}
+	return modfile.ModulePath(data)
+}
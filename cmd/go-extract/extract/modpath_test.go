@@ -0,0 +1,59 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveModules(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example/app\n\ngo 1.22\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "cmd", "server"), 0755)
+	src := filepath.Join(dir, "cmd", "server", "main.go")
+	os.WriteFile(src, []byte(`package main
+
+import (
+	"fmt"
+	"example/app/store"
+	"github.com/lib/pq"
+	"../../internal/auth"
+)
+`), 0644)
+
+	result := ExtractFiles([]string{src}, Options{})
+	ResolveModules(result)
+
+	if result.ModulePath != "example/app" {
+		t.Errorf("expected module path example/app, got %q", result.ModulePath)
+	}
+	expected := []struct {
+		path          string
+		std, internal bool
+	}{
+		{"fmt", true, false},
+		{"example/app/store", false, true},
+		{"github.com/lib/pq", false, false},
+		{"example/app/internal/auth", false, true},
+	}
+	for i, want := range expected {
+		got := result.Imports[i]
+		if got.Path != want.path || got.Std != want.std || got.Internal != want.internal {
+			t.Errorf("import %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+	if got := strings.Join(result.StdDependencies, ","); got != "fmt" {
+		t.Errorf("expected std dependencies fmt, got %s", got)
+	}
+}
+
+func TestResolveModulesWithoutGoMod(t *testing.T) {
+	result := newResult()
+	result.Imports = []ImportInfo{{File: "repo/a.go", Path: "./util"}}
+	ResolveModules(result)
+
+	if result.ModulePath != "" || result.Imports[0].Internal || result.Imports[0].Path != "./util" {
+		t.Errorf("expected imports of archive entries to be left alone, got %+v", result)
+	}
+}
@@ -0,0 +1,73 @@
+package extract
+
+import "time"
+
+// DefaultLargeParamBytes is the LargeParamBytes used when Options leaves it
+// zero.
+const DefaultLargeParamBytes = 128
+
+// Options configures an extraction. The zero value extracts every file with
+// no time limit and reports nothing about files that fail.
+//
+// SkipGenerated, Timeout, Template, IncludeFuncLits, LargeParamBytes,
+// FileError and Progress apply to every entry point that extracts files;
+// the remaining fields only to those documented as using them.
+type Options struct {
+	// SkipTests leaves out _test.go files; used by ExtractDir and StreamDir.
+	SkipTests bool
+	// ExportedOnly keeps only exported functions, methods of exported
+	// types, structs, interfaces, and values; used by ExtractDir and
+	// StreamDir.
+	ExportedOnly bool
+	// Workers is the number of files parsed concurrently by ExtractDir and
+	// StreamDir; zero means one per CPU.
+	Workers int
+	// FollowSymlinks makes ExtractDir and StreamDir descend into symlinked
+	// directories, which are otherwise skipped. A directory reached again,
+	// through a link cycle or a second link to it, is walked only the first
+	// time.
+	FollowSymlinks bool
+	// Process, when non-nil, is applied to each result after the analyses
+	// and ExportedOnly filtering: to the combined result in ExtractDir, and
+	// to each file's before it is written in StreamDir and WriteSplit.
+	Process func(*ExtractResult) *ExtractResult
+
+	// SkipGenerated gives generated files, those with a "// Code generated
+	// ... DO NOT EDIT." line before the package clause as go/ast.IsGenerated
+	// checks, an empty result.
+	SkipGenerated bool
+	// Timeout, when positive, bounds the time spent extracting each file
+	// read from disk; a file taking longer fails.
+	Timeout time.Duration
+	// Template treats files read from disk as Go text/template sources,
+	// stripping their actions before parsing.
+	Template bool
+	// IncludeFuncLits adds the function literals inside each function to
+	// the extracted functions.
+	IncludeFuncLits bool
+	// LargeParamBytes is the estimated size in bytes above which a struct
+	// passed by value is listed in LargeValueParams; zero means
+	// DefaultLargeParamBytes.
+	LargeParamBytes int
+	// FileError, when non-nil, is called with each input that could not be
+	// read, parsed, or written, which is then skipped. It is called from the
+	// goroutine that called the entry point, never concurrently.
+	FileError func(path string, err error)
+	// Progress, when non-nil, counts each file processed.
+	Progress *Progress
+}
+
+// fileError reports a failed input to o.FileError, if set.
+func (o Options) fileError(path string, err error) {
+	if o.FileError != nil {
+		o.FileError(path, err)
+	}
+}
+
+// largeParamBytes returns the LargeValueParams threshold o selects.
+func (o Options) largeParamBytes() int {
+	if o.LargeParamBytes > 0 {
+		return o.LargeParamBytes
+	}
+	return DefaultLargeParamBytes
+}
@@ -0,0 +1,258 @@
+package extract
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// outputWriter is the destination for a run's output, closed in order when
+// done so a gzip stream is flushed before its file is closed.
+type outputWriter struct {
+	io.Writer
+	closers []io.Closer
+}
+
+// Close closes the gzip stream and file behind w, if any, returning the
+// first error.
+func (w *outputWriter) Close() error {
+	var first error
+	for _, c := range w.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// openOutput returns a writer for path, or for stdout when path is empty.
+// Output is gzip-compressed when compress is set or path ends in .gz.
+func openOutput(path string, compress bool) (*outputWriter, error) {
+	out := &outputWriter{Writer: os.Stdout}
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("creating output: %w", err)
+		}
+		out.Writer = f
+		out.closers = append(out.closers, f)
+	}
+	if compress || strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(out.Writer)
+		out.Writer = gz
+		out.closers = append([]io.Closer{gz}, out.closers...)
+	}
+	return out, nil
+}
+
+// WriteOutput opens the output named by path and compress (see openOutput),
+// calls write with it, and closes it.
+func WriteOutput(path string, compress bool, write func(io.Writer) error) error {
+	out, err := openOutput(path, compress)
+	if err != nil {
+		return err
+	}
+	err = write(out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// WriteJSON writes result as indented JSON.
+func WriteJSON(w io.Writer, result *ExtractResult) error {
+	return WriteIndentedJSON(w, result)
+}
+
+// WriteIndentedJSON writes v as indented JSON.
+func WriteIndentedJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// WriteCSV writes one row per function, preceded by a header row. Structs and
+// interfaces are omitted: CSV output is meant for sorting function metrics in
+// a spreadsheet, and the entity kinds don't share a column layout.
+func WriteCSV(w io.Writer, result *ExtractResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"Name", "File", "Line", "LOC", "Receiver", "Exported", "Complexity"}); err != nil {
+		return err
+	}
+	for _, fn := range result.Functions {
+		row := []string{
+			fn.Name,
+			fn.File,
+			strconv.Itoa(fn.Line),
+			strconv.Itoa(fn.LOC),
+			fn.Receiver,
+			strconv.FormatBool(fn.Exported),
+			strconv.Itoa(fn.Complexity),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteCounts writes a one-line summary of the number of input files and of
+// each kind of extracted entity, for -count.
+func WriteCounts(w io.Writer, files int, result *ExtractResult) {
+	fmt.Fprintf(w, "files: %d, functions: %d, structs: %d, interfaces: %d, values: %d\n",
+		files, len(result.Functions), len(result.Structs), len(result.Interfaces), len(result.Values))
+}
+
+// CountFiles returns the number of distinct files extracted into result, for
+// inputs such as archives whose file count isn't known up front. Every parsed
+// file counts, including one with only a package clause or imports; files
+// that failed to parse, or were skipped as generated, do not. It counts
+// result.Files, so it must be called before they are cleared.
+func CountFiles(result *ExtractResult) int {
+	files := make(map[string]bool, len(result.Files))
+	for _, f := range result.Files {
+		files[f.File] = true
+	}
+	return len(files)
+}
+
+// GofmtBodies replaces each function body with its gofmt-formatted text, so
+// bodies, and their -body-hash digests, don't change with formatting alone.
+// A body is formatted as that of a top-level function, so one from a method
+// or function literal loses its original indentation too. Bodies that fail
+// to format, such as those of template files, are kept as they are.
+func GofmtBodies(result *ExtractResult) {
+	const prefix = "package p\n\nfunc _() "
+	for i, fn := range result.Functions {
+		if fn.Body == "" {
+			continue
+		}
+		out, err := format.Source([]byte(prefix + fn.Body))
+		if err != nil || !strings.HasPrefix(string(out), prefix) {
+			continue
+		}
+		result.Functions[i].Body = strings.TrimSuffix(string(out[len(prefix):]), "\n")
+	}
+}
+
+// HashBodies replaces each function body with the hex SHA-256 digest of its
+// text, keeping output compact while still revealing implementation changes.
+// Functions without a body (HasNoBody) keep an empty Body rather than all
+// sharing the digest of "".
+func HashBodies(result *ExtractResult) {
+	for i, fn := range result.Functions {
+		if fn.HasNoBody {
+			continue
+		}
+		sum := sha256.Sum256([]byte(fn.Body))
+		result.Functions[i].Body = hex.EncodeToString(sum[:])
+	}
+}
+
+// RelativizePaths rewrites every File field relative to base. Both are made
+// absolute first, so relative and absolute inputs mix freely. A path that
+// cannot be expressed relative to base (another volume on Windows) is kept
+// as given; the returned error, if any, joins one error per such path.
+func RelativizePaths(result *ExtractResult, base string) error {
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	failed := make(map[string]bool)
+	rel := func(path string) string {
+		abs, err := filepath.Abs(path)
+		if err == nil {
+			var r string
+			if r, err = filepath.Rel(absBase, abs); err == nil {
+				return r
+			}
+		}
+		if !failed[path] {
+			failed[path] = true
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+		return path
+	}
+	for i := range result.Functions {
+		result.Functions[i].File = rel(result.Functions[i].File)
+	}
+	for i := range result.Structs {
+		result.Structs[i].File = rel(result.Structs[i].File)
+	}
+	for i := range result.Interfaces {
+		result.Interfaces[i].File = rel(result.Interfaces[i].File)
+	}
+	for i := range result.Values {
+		result.Values[i].File = rel(result.Values[i].File)
+	}
+	for i := range result.Examples {
+		result.Examples[i].File = rel(result.Examples[i].File)
+	}
+	for i := range result.Assertions {
+		result.Assertions[i].File = rel(result.Assertions[i].File)
+	}
+	for i := range result.Files {
+		result.Files[i].File = rel(result.Files[i].File)
+	}
+	for i := range result.Imports {
+		result.Imports[i].File = rel(result.Imports[i].File)
+	}
+	return errors.Join(errs...)
+}
+
+// WriteSplit extracts each path on its own and writes its result, analyzed as
+// a one-file input and passed through opts.Process, to
+// <dir>/<basename>.<ext>, where basename drops the .go suffix. Cross-file
+// links, such as methods declared in another file of the package, are
+// therefore not made. dir is created if missing. Files that fail to parse are
+// reported to opts.FileError and produce no output; so is a file whose
+// basename repeats an earlier one, rather than overwriting it.
+func WriteSplit(paths []string, dir, ext string, write func(io.Writer, *ExtractResult) error, opts Options) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	written := make(map[string]string)
+	for _, path := range paths {
+		result, err := extractFile(path, opts)
+		opts.Progress.Increment()
+		if err != nil {
+			opts.fileError(path, err)
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(path), ".go") + "." + ext
+		if prev, ok := written[name]; ok {
+			opts.fileError(path, fmt.Errorf("skipped, %s already written for %s", name, prev))
+			continue
+		}
+		written[name] = path
+		analyze(result, opts)
+		if opts.Process != nil {
+			result = opts.Process(result)
+		}
+
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		err = write(f, result)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", name, err)
+		}
+	}
+	return nil
+}
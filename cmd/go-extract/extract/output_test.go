@@ -0,0 +1,380 @@
+package extract
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "Start", File: "server.go", Line: 10, LOC: 5, Receiver: "Server", Exported: true, Complexity: 3},
+			{Name: "helper", File: "util.go", Line: 3, LOC: 2, Complexity: 1},
+		},
+		Structs: []StructInfo{{Name: "Server"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, result); err != nil {
+		t.Fatalf("writeCSV failed: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV failed: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected header plus 2 rows, got %d", len(rows))
+	}
+	if rows[0][0] != "Name" || rows[0][5] != "Exported" || rows[0][6] != "Complexity" {
+		t.Errorf("unexpected header %v", rows[0])
+	}
+	expected := []string{"Start", "server.go", "10", "5", "Server", "true", "3"}
+	for i, want := range expected {
+		if rows[1][i] != want {
+			t.Errorf("column %d: expected %s, got %s", i, want, rows[1][i])
+		}
+	}
+	if rows[2][4] != "" {
+		t.Errorf("expected empty receiver for helper, got %s", rows[2][4])
+	}
+	if rows[2][6] != "1" {
+		t.Errorf("expected complexity 1 for helper, got %s", rows[2][6])
+	}
+}
+
+func TestWriteSplit(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	bad := filepath.Join(dir, "bad.go")
+	os.WriteFile(a, []byte("package main\n\nfunc A() {}\n"), 0644)
+	os.WriteFile(b, []byte("package main\n\nfunc B() {}\n"), 0644)
+	os.WriteFile(bad, []byte("package\n"), 0644)
+	out := filepath.Join(dir, "out", "nested")
+
+	var failed []string
+	if err := WriteSplit([]string{a, b, bad}, out, "json", WriteJSON, Options{FileError: recordFailures(&failed)}); err != nil {
+		t.Fatalf("writeSplit failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "a.json"))
+	if err != nil {
+		t.Fatalf("expected a.json: %v", err)
+	}
+	var result ExtractResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("decoding a.json failed: %v", err)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].Name != "A" {
+		t.Errorf("expected only A in a.json, got %v", result.Functions)
+	}
+	if _, err := os.Stat(filepath.Join(out, "b.json")); err != nil {
+		t.Errorf("expected b.json: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(out, "bad.json")); !os.IsNotExist(err) {
+		t.Errorf("expected no output for unparseable file")
+	}
+	if len(failed) != 1 || failed[0] != bad {
+		t.Errorf("expected bad.go reported as failed, got %v", failed)
+	}
+}
+
+func TestWriteSplitAnalyzes(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "s.go")
+	os.WriteFile(src, []byte(`package main
+
+import "os"
+
+type S struct{ N int }
+
+type Runner interface{ Run() }
+
+func (s *S) Run() {
+	s.N++
+	os.Remove("x")
+	os.Remove("y")
+}
+`), 0644)
+	out := filepath.Join(dir, "out")
+
+	if err := WriteSplit([]string{src}, out, "json", WriteJSON, Options{}); err != nil {
+		t.Fatalf("writeSplit failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "s.json"))
+	if err != nil {
+		t.Fatalf("expected s.json: %v", err)
+	}
+	var result ExtractResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("decoding s.json failed: %v", err)
+	}
+	if impl := result.Interfaces[0].Implementers; len(impl) != 1 || impl[0] != "S" {
+		t.Errorf("expected Runner implemented by S, got %v", impl)
+	}
+	if deps := result.Dependencies; len(deps) != 1 || deps[0] != "os" {
+		t.Errorf("expected dependencies [os], got %v", deps)
+	}
+	if n := result.Functions[0].IgnoredErrors; n != 2 {
+		t.Errorf("expected 2 ignored errors, got %d", n)
+	}
+	if usage := result.Structs[0].FieldUsage; len(usage) != 1 || usage[0] != 1 {
+		t.Errorf("expected field usage [1], got %v", usage)
+	}
+}
+
+func TestHashBodies(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "A", Body: "{\n\treturn 1\n}"},
+			{Name: "B", Body: "{\n\treturn 1\n}"},
+			{Name: "C", Body: "{\n\treturn 2\n}"},
+			{Name: "D", HasNoBody: true},
+		},
+	}
+
+	HashBodies(result)
+
+	a, b, c := result.Functions[0].Body, result.Functions[1].Body, result.Functions[2].Body
+	if len(a) != 64 {
+		t.Errorf("expected 64-character hex digest, got %q", a)
+	}
+	if a != b {
+		t.Errorf("expected identical bodies to hash identically")
+	}
+	if a == c {
+		t.Errorf("expected different bodies to hash differently")
+	}
+	if d := result.Functions[3].Body; d != "" {
+		t.Errorf("expected bodiless function to keep an empty body, got %q", d)
+	}
+}
+
+func TestGofmtBodies(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "A", Body: "{\n\t\tx:=1  // one\n\t\treturn x\n\t}"},
+			{Name: "B", Body: "{ return 1 }"},
+			{Name: "C", Body: "{ {{ .Body }} }"},
+			{Name: "D"},
+		},
+	}
+
+	GofmtBodies(result)
+
+	expected := []string{
+		"{\n\tx := 1 // one\n\treturn x\n}",
+		"{ return 1 }",
+		"{ {{ .Body }} }",
+		"",
+	}
+	for i, want := range expected {
+		if got := result.Functions[i].Body; got != want {
+			t.Errorf("expected %s body %q, got %q", result.Functions[i].Name, want, got)
+		}
+	}
+}
+
+func TestWriteStubs(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "server.go")
+	os.WriteFile(src, []byte(`package server
+
+import (
+	"context"
+	stdlog "log"
+	"net/http"
+	"time"
+)
+
+type Server struct{}
+
+func (s *Server) Start(ctx context.Context, addrs ...string) error { return nil }
+
+func (Server) Name() string { return "" }
+
+func New(string, int) (*Server, error) { return nil, nil }
+
+func Max[T int | float64](a, b T) T { return a }
+
+func (s *Server) Serve(h http.Handler, l *stdlog.Logger, opts []Option) {}
+
+type List[K comparable, V any] struct{}
+
+func (l *List[K, V]) Get(key K, timeout time.Duration) (V, bool) { var v V; return v, false }
+
+func Wrap(c Cache[string], b [4]byte) {}
+
+func helper() {}
+
+type conn struct{}
+
+func (c *conn) Close() {}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := WriteStubs(&buf, result); err != nil {
+		t.Fatalf("writeStubs failed: %v", err)
+	}
+
+	expected := `package server
+
+import (
+	"context"
+	stdlog "log"
+	"net/http"
+	"time"
+)
+
+type Server struct{}
+type Option struct{}
+type List[K comparable, V any] struct{}
+type Cache[T0 any] struct{}
+
+func (*Server) Start(ctx context.Context, addrs ...string) error {
+	panic("not implemented")
+}
+
+func (Server) Name() string {
+	panic("not implemented")
+}
+
+func New(_ string, _ int) (*Server, error) {
+	panic("not implemented")
+}
+
+func Max[T int | float64](a T, b T) T {
+	panic("not implemented")
+}
+
+func (*Server) Serve(h http.Handler, l *stdlog.Logger, opts []Option) {
+	panic("not implemented")
+}
+
+func (*List[K, V]) Get(key K, timeout time.Duration) (V, bool) {
+	panic("not implemented")
+}
+
+func Wrap(c Cache[string], b [4]byte) {
+	panic("not implemented")
+}
+`
+	if buf.String() != expected {
+		t.Errorf("expected stubs:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "stubs.go", buf.Bytes(), 0)
+	if err != nil {
+		t.Fatalf("parsing stubs failed: %v", err)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("server", fset, []*ast.File{file}, nil); err != nil {
+		t.Errorf("type-checking stubs failed: %v", err)
+	}
+}
+
+func TestOpenOutputGzip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json.gz")
+
+	out, err := openOutput(path, false)
+	if err != nil {
+		t.Fatalf("openOutput failed: %v", err)
+	}
+	if err := WriteJSON(out, newResult()); err != nil {
+		t.Fatalf("writeJSON failed: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening output: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("expected gzip output: %v", err)
+	}
+	var result ExtractResult
+	if err := json.NewDecoder(gz).Decode(&result); err != nil {
+		t.Errorf("expected JSON inside gzip: %v", err)
+	}
+
+	plain := filepath.Join(dir, "out.json")
+	out, err = openOutput(plain, false)
+	if err != nil {
+		t.Fatalf("openOutput failed: %v", err)
+	}
+	WriteJSON(out, newResult())
+	out.Close()
+	data, _ := os.ReadFile(plain)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		t.Errorf("expected uncompressed JSON, got %q", data[:2])
+	}
+}
+
+func TestRelativizePaths(t *testing.T) {
+	base := t.TempDir()
+	abs := filepath.Join(base, "pkg", "a.go")
+	result := &ExtractResult{
+		Functions:  []FunctionInfo{{Name: "A", File: abs}},
+		Structs:    []StructInfo{{Name: "S", File: abs}},
+		Interfaces: []InterfaceInfo{{Name: "I", File: abs}},
+		Values:     []ValueInfo{{Name: "V", File: filepath.Join(base, "b.go")}},
+	}
+
+	if err := RelativizePaths(result, base); err != nil {
+		t.Fatalf("RelativizePaths failed: %v", err)
+	}
+
+	want := filepath.Join("pkg", "a.go")
+	if result.Functions[0].File != want || result.Structs[0].File != want || result.Interfaces[0].File != want {
+		t.Errorf("expected %s everywhere, got %s, %s, %s", want, result.Functions[0].File, result.Structs[0].File, result.Interfaces[0].File)
+	}
+	if result.Values[0].File != "b.go" {
+		t.Errorf("expected b.go, got %s", result.Values[0].File)
+	}
+}
+
+func TestWriteCounts(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"a.go":   "package app\n\ntype S struct{}\n\nfunc A() {}\n",
+		"b.go":   "package app\n\nvar V = 1\n",
+		"doc.go": "// Package app does things.\npackage app\n",
+		"imp.go": "package app\n\nimport _ \"embed\"\n",
+		"bad.go": "package",
+	})
+	result, err := ExtractDir(dir, Options{})
+	if err != nil {
+		t.Fatalf("ExtractDir failed: %v", err)
+	}
+	if got := CountFiles(result); got != 4 {
+		t.Errorf("expected 4 files, including those with no entities, got %d", got)
+	}
+
+	var buf bytes.Buffer
+	WriteCounts(&buf, 5, result)
+	expected := "files: 5, functions: 1, structs: 1, interfaces: 0, values: 1\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
@@ -0,0 +1,68 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressInterval is how often the progress line is redrawn.
+const ProgressInterval = 200 * time.Millisecond
+
+// Progress reports how many of a known number of files have been processed,
+// redrawing a single line on w at a fixed interval. The counter is updated
+// atomically, so Increment is safe to call from concurrent extractions. A nil
+// *Progress is valid and reports nothing.
+type Progress struct {
+	w     io.Writer
+	total int
+	done  atomic.Int64
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewProgress starts reporting progress toward total files on w.
+func NewProgress(w io.Writer, total int, interval time.Duration) *Progress {
+	p := &Progress{w: w, total: total, stop: make(chan struct{})}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.draw()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// Increment records one more processed file.
+func (p *Progress) Increment() {
+	if p == nil {
+		return
+	}
+	p.done.Add(1)
+}
+
+// Finish stops the periodic redraw and prints the final count on its own line.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+	p.draw()
+	fmt.Fprintln(p.w)
+}
+
+// draw rewrites the progress line in place.
+func (p *Progress) draw() {
+	fmt.Fprintf(p.w, "\rprocessed %d/%d files", p.done.Load(), p.total)
+}
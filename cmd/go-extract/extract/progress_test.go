@@ -0,0 +1,54 @@
+package extract
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for the progress goroutine to write to
+// while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestProgressConcurrentIncrements(t *testing.T) {
+	var out syncBuffer
+	p := NewProgress(&out, 100, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.Increment()
+		}()
+	}
+	wg.Wait()
+	p.Finish()
+
+	if !strings.HasSuffix(out.String(), "processed 100/100 files\n") {
+		t.Errorf("expected final count line, got %q", out.String())
+	}
+}
+
+func TestProgressNilIsNoop(t *testing.T) {
+	var p *Progress
+	p.Increment()
+	p.Finish()
+}
@@ -0,0 +1,94 @@
+package extract
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// promKey identifies a package by its directory and name, so directories
+// sharing a package name, such as internal/util and pkg/util or every main,
+// are reported separately.
+type promKey struct{ dir, pkg string }
+
+// promStats holds the aggregate counts reported for one package by -format prom.
+type promStats struct {
+	functions, structs, interfaces int
+	loc, complexity                int
+}
+
+// promLabelEscaper escapes a label value with the only three escapes the
+// Prometheus text format allows.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteProm writes aggregate counts per package in the Prometheus text
+// exposition format, for scraping codebase metrics over time. Each metric is
+// a gauge labelled with the package's directory, as in the File fields, and
+// name, packages in order of directory and then name. LOC is
+// the total number of lines spanned by functions and methods, and average
+// complexity is their mean cyclomatic complexity, 0 for a package without
+// functions. Function literals extracted with -include-funclits are left
+// out, since their enclosing function already spans their lines.
+func WriteProm(w io.Writer, result *ExtractResult) error {
+	stats := make(map[promKey]*promStats)
+	get := func(file, pkg string) *promStats {
+		key := promKey{filepath.ToSlash(filepath.Dir(file)), pkg}
+		if stats[key] == nil {
+			stats[key] = &promStats{}
+		}
+		return stats[key]
+	}
+	for _, fn := range result.Functions {
+		if fn.IsFuncLit {
+			continue
+		}
+		s := get(fn.File, fn.Package)
+		s.functions++
+		s.loc += fn.LOC
+		s.complexity += fn.Complexity
+	}
+	for _, st := range result.Structs {
+		get(st.File, st.Package).structs++
+	}
+	for _, iface := range result.Interfaces {
+		get(iface.File, iface.Package).interfaces++
+	}
+	pkgs := make([]promKey, 0, len(stats))
+	for key := range stats {
+		pkgs = append(pkgs, key)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		if pkgs[i].dir != pkgs[j].dir {
+			return pkgs[i].dir < pkgs[j].dir
+		}
+		return pkgs[i].pkg < pkgs[j].pkg
+	})
+
+	metrics := []struct {
+		name, help string
+		value      func(*promStats) string
+	}{
+		{"goextract_functions", "Number of functions and methods.", func(s *promStats) string { return fmt.Sprint(s.functions) }},
+		{"goextract_structs", "Number of struct types.", func(s *promStats) string { return fmt.Sprint(s.structs) }},
+		{"goextract_interfaces", "Number of interface types.", func(s *promStats) string { return fmt.Sprint(s.interfaces) }},
+		{"goextract_loc", "Total lines spanned by functions and methods.", func(s *promStats) string { return fmt.Sprint(s.loc) }},
+		{"goextract_avg_complexity", "Mean cyclomatic complexity of functions and methods.", func(s *promStats) string {
+			if s.functions == 0 {
+				return "0"
+			}
+			return fmt.Sprintf("%g", float64(s.complexity)/float64(s.functions))
+		}},
+	}
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", m.name)
+		for _, pkg := range pkgs {
+			fmt.Fprintf(&b, "%s{dir=\"%s\",package=\"%s\"} %s\n", m.name, promLabelEscaper.Replace(pkg.dir), promLabelEscaper.Replace(pkg.pkg), m.value(stats[pkg]))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
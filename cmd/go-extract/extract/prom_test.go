@@ -0,0 +1,101 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteProm(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	os.WriteFile(a, []byte(`package alpha
+
+type Server struct{}
+
+type Handler interface{ Handle() }
+
+func (s *Server) Start(ok bool) {
+	if ok {
+		return
+	}
+}
+
+func stop() {}
+`), 0644)
+	b := filepath.Join(dir, "b.go")
+	os.WriteFile(b, []byte(`package beta
+
+type Empty struct{}
+`), 0644)
+
+	result := ExtractFiles([]string{a, b}, Options{})
+	var out strings.Builder
+	if err := WriteProm(&out, result); err != nil {
+		t.Fatalf("writeProm failed: %v", err)
+	}
+
+	label := func(pkg string) string {
+		return `{dir="` + filepath.ToSlash(dir) + `",package="` + pkg + `"}`
+	}
+	expected := "# HELP goextract_functions Number of functions and methods.\n" +
+		"# TYPE goextract_functions gauge\n" +
+		"goextract_functions" + label("alpha") + " 2\n" +
+		"goextract_functions" + label("beta") + " 0\n" +
+		"# HELP goextract_structs Number of struct types.\n" +
+		"# TYPE goextract_structs gauge\n" +
+		"goextract_structs" + label("alpha") + " 1\n" +
+		"goextract_structs" + label("beta") + " 1\n" +
+		"# HELP goextract_interfaces Number of interface types.\n" +
+		"# TYPE goextract_interfaces gauge\n" +
+		"goextract_interfaces" + label("alpha") + " 1\n" +
+		"goextract_interfaces" + label("beta") + " 0\n" +
+		"# HELP goextract_loc Total lines spanned by functions and methods.\n" +
+		"# TYPE goextract_loc gauge\n" +
+		"goextract_loc" + label("alpha") + " 6\n" +
+		"goextract_loc" + label("beta") + " 0\n" +
+		"# HELP goextract_avg_complexity Mean cyclomatic complexity of functions and methods.\n" +
+		"# TYPE goextract_avg_complexity gauge\n" +
+		"goextract_avg_complexity" + label("alpha") + " 1.5\n" +
+		"goextract_avg_complexity" + label("beta") + " 0\n"
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestWritePromEscapesLabels(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{{Name: "f", File: "x.go", Package: "a\"b\\c\nd\té"}},
+	}
+	var out strings.Builder
+	if err := WriteProm(&out, result); err != nil {
+		t.Fatalf("writeProm failed: %v", err)
+	}
+	want := `goextract_functions{dir=".",package="a\"b\\c\nd` + "\té" + `"} 1`
+	if !strings.Contains(out.String(), want+"\n") {
+		t.Errorf("expected line %s, got:\n%s", want, out.String())
+	}
+}
+
+func TestWritePromSeparatesDirectories(t *testing.T) {
+	result := &ExtractResult{
+		Functions: []FunctionInfo{
+			{Name: "A", File: "internal/util/a.go", Package: "util"},
+			{Name: "B", File: "pkg/util/b.go", Package: "util"},
+			{Name: "C", File: "pkg/util/c.go", Package: "util"},
+		},
+	}
+	var out strings.Builder
+	if err := WriteProm(&out, result); err != nil {
+		t.Fatalf("writeProm failed: %v", err)
+	}
+	for _, want := range []string{
+		`goextract_functions{dir="internal/util",package="util"} 1`,
+		`goextract_functions{dir="pkg/util",package="util"} 2`,
+	} {
+		if !strings.Contains(out.String(), want+"\n") {
+			t.Errorf("expected line %s, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package extract
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// protoSchemaSource is the schema of -format proto output. It is the single
+// source of field numbers: encodeProto looks each Go field up by its JSON
+// name.
+//
+//go:embed extract.proto
+var protoSchemaSource string
+
+// protoField is one field of a message in extract.proto.
+type protoField struct {
+	number   int
+	typ      string
+	repeated bool
+}
+
+// protoSchema maps message names to their fields, keyed by field name.
+type protoSchema map[string]map[string]protoField
+
+var (
+	loadSchemaOnce sync.Once
+	loadedSchema   protoSchema
+	loadSchemaErr  error
+)
+
+// extractSchema returns the parsed extract.proto.
+func extractSchema() (protoSchema, error) {
+	loadSchemaOnce.Do(func() {
+		loadedSchema, loadSchemaErr = parseProtoSchema(protoSchemaSource)
+	})
+	return loadedSchema, loadSchemaErr
+}
+
+// parseProtoSchema parses the subset of proto3 extract.proto uses: flat
+// messages of "[repeated|optional] type name = number;" fields.
+func parseProtoSchema(src string) (protoSchema, error) {
+	schema := make(protoSchema)
+	var fields map[string]protoField
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(text, "//"); i >= 0 {
+			text = strings.TrimSpace(text[:i])
+		}
+		switch {
+		case text == "", strings.HasPrefix(text, "syntax "), strings.HasPrefix(text, "package "):
+		case strings.HasPrefix(text, "message ") && strings.HasSuffix(text, "{"):
+			name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(text, "message "), "{"))
+			fields = make(map[string]protoField)
+			schema[name] = fields
+		case text == "}":
+			fields = nil
+		default:
+			if fields == nil {
+				return nil, fmt.Errorf("extract.proto:%d: unexpected %q", line, text)
+			}
+			words := strings.Fields(strings.NewReplacer("=", " = ", ";", " ").Replace(text))
+			f := protoField{}
+			if words[0] == "repeated" || words[0] == "optional" {
+				f.repeated = words[0] == "repeated"
+				words = words[1:]
+			}
+			if len(words) != 4 || words[2] != "=" {
+				return nil, fmt.Errorf("extract.proto:%d: malformed field %q", line, text)
+			}
+			n, err := strconv.Atoi(words[3])
+			if err != nil {
+				return nil, fmt.Errorf("extract.proto:%d: bad field number %q", line, words[3])
+			}
+			f.number, f.typ = n, words[0]
+			fields[words[1]] = f
+		}
+	}
+	return schema, scanner.Err()
+}
+
+// WriteProto writes result as one ExtractResult message preceded by its
+// varint length, so repeated writes, as in -watch, form a length-delimited
+// stream.
+func WriteProto(w io.Writer, result *ExtractResult) error {
+	schema, err := extractSchema()
+	if err != nil {
+		return err
+	}
+	msg, err := encodeProto(schema, reflect.ValueOf(*result))
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(binary.AppendUvarint(nil, uint64(len(msg)))); err != nil {
+		return err
+	}
+	_, err = w.Write(msg)
+	return err
+}
+
+// encodeProto encodes the struct v as the message of the same name. Fields
+// are matched by JSON name; exported fields missing from the schema are an
+// error, so the schema cannot silently fall behind the Go types. Zero scalars
+// are omitted as in proto3, nil pointers mark an absent optional field, and
+// repeated scalars are packed.
+func encodeProto(schema protoSchema, v reflect.Value) ([]byte, error) {
+	t := v.Type()
+	fields, ok := schema[t.Name()]
+	if !ok {
+		return nil, fmt.Errorf("extract.proto has no message %s", t.Name())
+	}
+	var buf []byte
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		f, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("extract.proto: message %s has no field %s", t.Name(), name)
+		}
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Pointer:
+			if fv.IsNil() {
+				continue
+			}
+			var err error
+			if buf, err = appendProtoValue(schema, buf, f.number, fv.Elem(), true); err != nil {
+				return nil, err
+			}
+		case reflect.Slice:
+			var err error
+			if buf, err = appendProtoRepeated(schema, buf, f.number, fv); err != nil {
+				return nil, err
+			}
+		default:
+			var err error
+			if buf, err = appendProtoValue(schema, buf, f.number, fv, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+}
+
+// appendProtoRepeated appends the slice v as field number: scalars packed
+// into one length-delimited record, strings and messages one record each.
+func appendProtoRepeated(schema protoSchema, buf []byte, number int, v reflect.Value) ([]byte, error) {
+	if v.Len() == 0 {
+		return buf, nil
+	}
+	switch v.Type().Elem().Kind() {
+	case reflect.Int, reflect.Int64, reflect.Bool, reflect.Float64:
+		var packed []byte
+		for i := 0; i < v.Len(); i++ {
+			packed = appendProtoScalar(packed, v.Index(i))
+		}
+		buf = appendProtoTag(buf, number, 2)
+		buf = binary.AppendUvarint(buf, uint64(len(packed)))
+		return append(buf, packed...), nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		var err error
+		if buf, err = appendProtoValue(schema, buf, number, v.Index(i), true); err != nil {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// appendProtoValue appends v as field number. Zero scalars are skipped
+// unless present is set, as for optional fields and repeated elements.
+func appendProtoValue(schema protoSchema, buf []byte, number int, v reflect.Value, present bool) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() == 0 && !present {
+			return buf, nil
+		}
+		buf = appendProtoTag(buf, number, 2)
+		buf = binary.AppendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.String()...), nil
+	case reflect.Struct:
+		msg, err := encodeProto(schema, v)
+		if err != nil {
+			return nil, err
+		}
+		buf = appendProtoTag(buf, number, 2)
+		buf = binary.AppendUvarint(buf, uint64(len(msg)))
+		return append(buf, msg...), nil
+	case reflect.Int, reflect.Int64, reflect.Bool:
+		if v.IsZero() && !present {
+			return buf, nil
+		}
+		return appendProtoScalar(appendProtoTag(buf, number, 0), v), nil
+	case reflect.Float64:
+		if v.IsZero() && !present {
+			return buf, nil
+		}
+		return appendProtoScalar(appendProtoTag(buf, number, 1), v), nil
+	}
+	return nil, fmt.Errorf("cannot encode %s as protobuf", v.Type())
+}
+
+// appendProtoTag appends the key of field number with the given wire type.
+func appendProtoTag(buf []byte, number, wireType int) []byte {
+	return binary.AppendUvarint(buf, uint64(number)<<3|uint64(wireType))
+}
+
+// appendProtoScalar appends the bare encoding of an int64, bool, or double:
+// a varint (negative ints take ten bytes, as for proto int64) or, for
+// doubles, eight little-endian bytes.
+func appendProtoScalar(buf []byte, v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1)
+		}
+		return append(buf, 0)
+	case reflect.Float64:
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(v.Float()))
+	}
+	return binary.AppendUvarint(buf, uint64(v.Int()))
+}
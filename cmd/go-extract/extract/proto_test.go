@@ -0,0 +1,182 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// protoRecord is one decoded field of a protobuf message.
+type protoRecord struct {
+	number, wireType int
+	varint           uint64
+	data             []byte
+}
+
+// decodeProto splits a protobuf message into its field records.
+func decodeProto(t *testing.T, b []byte) []protoRecord {
+	t.Helper()
+	var records []protoRecord
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			t.Fatalf("bad key")
+		}
+		b = b[n:]
+		r := protoRecord{number: int(key >> 3), wireType: int(key & 7)}
+		switch r.wireType {
+		case 0:
+			r.varint, n = binary.Uvarint(b)
+			b = b[n:]
+		case 1:
+			r.data, b = b[:8], b[8:]
+		case 2:
+			size, n := binary.Uvarint(b)
+			r.data, b = b[n:n+int(size)], b[n+int(size):]
+		default:
+			t.Fatalf("unexpected wire type %d", r.wireType)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestProtoSchemaMatchesTypes(t *testing.T) {
+	schema, err := extractSchema()
+	if err != nil {
+		t.Fatalf("parsing extract.proto failed: %v", err)
+	}
+	kinds := map[reflect.Kind]string{
+		reflect.String: "string", reflect.Int: "int64", reflect.Int64: "int64",
+		reflect.Bool: "bool", reflect.Float64: "double",
+	}
+	seen := make(map[string]bool)
+	var check func(rt reflect.Type)
+	check = func(rt reflect.Type) {
+		if seen[rt.Name()] {
+			return
+		}
+		seen[rt.Name()] = true
+		fields, ok := schema[rt.Name()]
+		if !ok {
+			t.Errorf("extract.proto has no message %s", rt.Name())
+			return
+		}
+		numbers := make(map[int]string)
+		for name, f := range fields {
+			if other, dup := numbers[f.number]; dup {
+				t.Errorf("%s: fields %s and %s share number %d", rt.Name(), name, other, f.number)
+			}
+			numbers[f.number] = name
+		}
+		for i := 0; i < rt.NumField(); i++ {
+			sf := rt.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+			f, ok := fields[name]
+			if !ok {
+				t.Errorf("%s.%s: missing from extract.proto", rt.Name(), name)
+				continue
+			}
+			ft := sf.Type
+			if ft.Kind() == reflect.Slice != f.repeated {
+				t.Errorf("%s.%s: repeated mismatch", rt.Name(), name)
+			}
+			if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			want := kinds[ft.Kind()]
+			if ft.Kind() == reflect.Struct {
+				want = ft.Name()
+				check(ft)
+			}
+			if f.typ != want {
+				t.Errorf("%s.%s: expected proto type %s, got %s", rt.Name(), name, want, f.typ)
+			}
+		}
+	}
+	check(reflect.TypeOf(ExtractResult{}))
+}
+
+func TestWriteProto(t *testing.T) {
+	metric := 2.5
+	result := newResult()
+	result.Functions = []FunctionInfo{{
+		Name:         "Start",
+		Line:         12,
+		IsVariadic:   true,
+		ParamTypes:   []string{"int", ""},
+		CustomMetric: &metric,
+		Halstead:     HalsteadMetrics{Volume: 1.5},
+	}}
+	result.Structs = []StructInfo{{Name: "Server", FieldUsage: []int{3, 0, 300}}}
+	result.Dependencies = []string{"fmt"}
+
+	var buf bytes.Buffer
+	if err := WriteProto(&buf, result); err != nil {
+		t.Fatalf("writeProto failed: %v", err)
+	}
+	if err := WriteProto(&buf, newResult()); err != nil {
+		t.Fatalf("writeProto failed: %v", err)
+	}
+
+	stream := buf.Bytes()
+	size, n := binary.Uvarint(stream)
+	msg := stream[n : n+int(size)]
+	if rest := stream[n+int(size):]; !bytes.Equal(rest, []byte{0}) {
+		t.Errorf("expected the empty second message to be a zero length, got %v", rest)
+	}
+
+	top := decodeProto(t, msg)
+	if len(top) != 3 || top[0].number != 1 || top[1].number != 2 || top[2].number != 7 || string(top[2].data) != "fmt" {
+		t.Fatalf("unexpected top-level records %+v", top)
+	}
+
+	fn := make(map[int][]protoRecord)
+	for _, r := range decodeProto(t, top[0].data) {
+		fn[r.number] = append(fn[r.number], r)
+	}
+	if string(fn[2][0].data) != "Start" || fn[7][0].varint != 12 || fn[20][0].varint != 1 {
+		t.Errorf("unexpected name, line, or is_variadic: %+v", fn)
+	}
+	if len(fn[18]) != 2 || string(fn[18][0].data) != "int" || len(fn[18][1].data) != 0 {
+		t.Errorf("expected both param types, including the empty one, got %+v", fn[18])
+	}
+	if got := math.Float64frombits(binary.LittleEndian.Uint64(fn[52][0].data)); got != 2.5 {
+		t.Errorf("expected custom_metric 2.5, got %v", got)
+	}
+	halstead := decodeProto(t, fn[28][0].data)
+	if len(halstead) != 1 || halstead[0].number != 7 {
+		t.Errorf("expected only volume in halstead, got %+v", halstead)
+	}
+	if _, ok := fn[3]; ok {
+		t.Errorf("expected the empty file field to be omitted")
+	}
+
+	var usage []protoRecord
+	for _, r := range decodeProto(t, top[1].data) {
+		if r.number == 17 {
+			usage = append(usage, r)
+		}
+	}
+	if len(usage) != 1 || !bytes.Equal(usage[0].data, []byte{3, 0, 0xac, 0x02}) {
+		t.Errorf("expected packed field usage 3,0,300, got %+v", usage)
+	}
+}
+
+func TestParseProtoSchemaErrors(t *testing.T) {
+	for _, src := range []string{
+		"string stray = 1;",
+		"message M {\n  string name 1;\n}",
+		"message M {\n  string name = one;\n}",
+	} {
+		if _, err := parseProtoSchema(src); err == nil {
+			t.Errorf("expected an error for %q", src)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package extract
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// SatisfiesInfo reports whether one struct satisfies the interface named by
+// -satisfies.
+type SatisfiesInfo struct {
+	Struct      string   `json:"struct"`
+	File        string   `json:"file"`
+	Package     string   `json:"package"`
+	Line        int      `json:"line"`
+	Interface   string   `json:"interface"`
+	Satisfies   bool     `json:"satisfies"`
+	PointerOnly bool     `json:"pointer_only"`
+	Missing     []string `json:"missing"`
+	Mismatched  []string `json:"mismatched"`
+}
+
+// knownInterfaces holds the method sets of common standard library
+// interfaces, so -satisfies works without scanning their source. Embedded
+// interfaces are spelled out.
+var knownInterfaces = map[string][]string{
+	"error":                    {"Error() string"},
+	"fmt.Stringer":             {"String() string"},
+	"fmt.GoStringer":           {"GoString() string"},
+	"io.Reader":                {"Read([]byte) (int, error)"},
+	"io.Writer":                {"Write([]byte) (int, error)"},
+	"io.Closer":                {"Close() error"},
+	"io.Seeker":                {"Seek(int64, int) (int64, error)"},
+	"io.ReaderAt":              {"ReadAt([]byte, int64) (int, error)"},
+	"io.WriterAt":              {"WriteAt([]byte, int64) (int, error)"},
+	"io.ReaderFrom":            {"ReadFrom(io.Reader) (int64, error)"},
+	"io.WriterTo":              {"WriteTo(io.Writer) (int64, error)"},
+	"io.ByteReader":            {"ReadByte() (byte, error)"},
+	"io.ByteWriter":            {"WriteByte(byte) error"},
+	"io.StringWriter":          {"WriteString(string) (int, error)"},
+	"io.ReadWriter":            {"Read([]byte) (int, error)", "Write([]byte) (int, error)"},
+	"io.ReadCloser":            {"Read([]byte) (int, error)", "Close() error"},
+	"io.WriteCloser":           {"Write([]byte) (int, error)", "Close() error"},
+	"io.ReadWriteCloser":       {"Read([]byte) (int, error)", "Write([]byte) (int, error)", "Close() error"},
+	"io.ReadSeeker":            {"Read([]byte) (int, error)", "Seek(int64, int) (int64, error)"},
+	"sort.Interface":           {"Len() int", "Less(int, int) bool", "Swap(int, int)"},
+	"heap.Interface":           {"Len() int", "Less(int, int) bool", "Swap(int, int)", "Push(any)", "Pop() any"},
+	"http.Handler":             {"ServeHTTP(http.ResponseWriter, *http.Request)"},
+	"json.Marshaler":           {"MarshalJSON() ([]byte, error)"},
+	"json.Unmarshaler":         {"UnmarshalJSON([]byte) error"},
+	"encoding.TextMarshaler":   {"MarshalText() ([]byte, error)"},
+	"encoding.TextUnmarshaler": {"UnmarshalText([]byte) error"},
+	"flag.Value":               {"String() string", "Set(string) error"},
+}
+
+// interfaceSignatures returns the method signatures of the interface called
+// name, e.g. "Read([]byte) (int, error)". Interfaces in the scanned set,
+// matched by name or package-qualified name, take precedence over
+// knownInterfaces, so a file declaring the interface can be scanned alongside
+// the structs. Methods of embedded interfaces are not included for scanned
+// interfaces.
+func interfaceSignatures(result *ExtractResult, name string) ([]string, error) {
+	for _, iface := range result.Interfaces {
+		if iface.Name == name || iface.Package+"."+iface.Name == name {
+			return iface.Signatures, nil
+		}
+	}
+	if sigs, ok := knownInterfaces[name]; ok {
+		return sigs, nil
+	}
+	return nil, fmt.Errorf("unknown interface %q: scan a file that declares it", name)
+}
+
+// CheckSatisfies reports, for every struct in result, whether its methods
+// cover the interface called name with matching signatures. Signatures are
+// compared textually after normalizing interface{} to any, so a parameter
+// spelled with a different import alias counts as a mismatch. A struct that
+// only satisfies the interface through pointer-receiver methods is flagged
+// PointerOnly: *T satisfies it but T does not. Methods declared on an alias
+// count for the struct it names, as in attachMethods.
+func CheckSatisfies(result *ExtractResult, name string) ([]SatisfiesInfo, error) {
+	want, err := interfaceSignatures(result, name)
+	if err != nil {
+		return nil, err
+	}
+
+	type method struct {
+		sig     string
+		pointer bool
+	}
+	aliases := newAliasTargets(result)
+	methods := make(map[receiverKey]map[string]method)
+	for _, fn := range result.Functions {
+		if fn.Receiver == "" {
+			continue
+		}
+		key := aliases.receiverOf(fn)
+		if methods[key] == nil {
+			methods[key] = make(map[string]method)
+		}
+		methods[key][fn.Name] = method{methodSignature(fn), fn.PointerReceiver}
+	}
+
+	infos := []SatisfiesInfo{}
+	for _, s := range result.Structs {
+		info := SatisfiesInfo{
+			Struct:     s.Name,
+			File:       s.File,
+			Package:    s.Package,
+			Line:       s.Line,
+			Interface:  name,
+			Missing:    []string{},
+			Mismatched: []string{},
+		}
+		have := methods[receiverKey{filepath.Dir(s.File), s.Package, s.Name}]
+		pointer := false
+		for _, sig := range want {
+			m, ok := have[sigName(sig)]
+			switch {
+			case !ok:
+				info.Missing = append(info.Missing, sigName(sig))
+			case normalizeSignature(m.sig) != normalizeSignature(sig):
+				info.Mismatched = append(info.Mismatched, fmt.Sprintf("have %s, want %s", m.sig, sig))
+			case m.pointer:
+				pointer = true
+			}
+		}
+		info.Satisfies = len(info.Missing) == 0 && len(info.Mismatched) == 0
+		info.PointerOnly = info.Satisfies && pointer
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// methodSignature renders fn in interface method form, e.g.
+// "Read([]byte) (int, error)".
+func methodSignature(fn FunctionInfo) string {
+	sig := fn.Name + "(" + strings.Join(fn.ParamTypes, ", ") + ")"
+	switch len(fn.Results) {
+	case 0:
+	case 1:
+		sig += " " + fn.Results[0]
+	default:
+		sig += " (" + strings.Join(fn.Results, ", ") + ")"
+	}
+	return sig
+}
+
+// sigName returns the method name of an interface method signature.
+func sigName(sig string) string {
+	name, _, _ := strings.Cut(sig, "(")
+	return name
+}
+
+// normalizeSignature rewrites interface{} to any so the two spellings of the
+// empty interface compare equal.
+func normalizeSignature(sig string) string {
+	return strings.ReplaceAll(sig, "interface{}", "any")
+}
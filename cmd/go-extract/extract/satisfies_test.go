@@ -0,0 +1,75 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSatisfiesKnownInterface(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "readers.go")
+	os.WriteFile(src, []byte(`package main
+
+type File struct{}
+
+func (f *File) Read(p []byte) (int, error) { return 0, nil }
+
+type Buffer struct{}
+
+func (b Buffer) Read(p []byte) (n int, err error) { return 0, nil }
+
+type Line struct{}
+
+func (l Line) Read() string { return "" }
+
+type Config struct{}
+`), 0644)
+
+	result := ExtractFiles([]string{src}, Options{})
+	infos, err := CheckSatisfies(result, "io.Reader")
+	if err != nil {
+		t.Fatalf("checkSatisfies failed: %v", err)
+	}
+	if len(infos) != 4 {
+		t.Fatalf("expected 4 structs, got %+v", infos)
+	}
+	file, buffer, line, config := infos[0], infos[1], infos[2], infos[3]
+	if !file.Satisfies || !file.PointerOnly {
+		t.Errorf("expected File to satisfy via pointer, got %+v", file)
+	}
+	if !buffer.Satisfies || buffer.PointerOnly {
+		t.Errorf("expected Buffer to satisfy by value, got %+v", buffer)
+	}
+	if line.Satisfies || len(line.Mismatched) != 1 || !strings.Contains(line.Mismatched[0], "have Read() string") {
+		t.Errorf("expected Line to mismatch Read, got %+v", line)
+	}
+	if config.Satisfies || strings.Join(config.Missing, ",") != "Read" {
+		t.Errorf("expected Config to miss Read, got %+v", config)
+	}
+}
+
+func TestCheckSatisfiesScannedInterface(t *testing.T) {
+	dir := t.TempDir()
+	iface := filepath.Join(dir, "store.go")
+	impl := filepath.Join(dir, "memory.go")
+	os.WriteFile(iface, []byte("package store\n\ntype Store interface {\n\tGet(key string) (any, bool)\n\tPut(key string, v interface{})\n\tLen() int\n}\n"), 0644)
+	os.WriteFile(impl, []byte("package store\n\ntype Memory struct{}\n\nfunc (m *Memory) Get(k string) (interface{}, bool) { return nil, false }\n\nfunc (m *Memory) Put(k string, v any) {}\n"), 0644)
+
+	result := ExtractFiles([]string{iface, impl}, Options{})
+	infos, err := CheckSatisfies(result, "store.Store")
+	if err != nil {
+		t.Fatalf("checkSatisfies failed: %v", err)
+	}
+	memory := infos[0]
+	if memory.Satisfies || strings.Join(memory.Missing, ",") != "Len" || len(memory.Mismatched) != 0 {
+		t.Errorf("expected Memory to miss only Len, got %+v", memory)
+	}
+}
+
+func TestCheckSatisfiesUnknownInterface(t *testing.T) {
+	if _, err := CheckSatisfies(newResult(), "pkg.Missing"); err == nil {
+		t.Errorf("expected an error for an unknown interface")
+	}
+}
@@ -0,0 +1,264 @@
+package extract
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteStubs writes a Go stub for every exported function and method: its
+// signature with a panic("not implemented") body. Each package becomes one
+// compilable file section, in first-seen order: a package clause, the
+// imports the stubs' types need, taken from the import declarations of the
+// files they came from, and an empty struct declaration for every type of
+// the package the stubs mention, receivers included. Those types keep the
+// type parameters of the scanned struct of that name, if any, or otherwise
+// get one any-constrained parameter per type argument. A function or method
+// declared more than once, as in per-platform files, is stubbed once.
+// Parameters keep their names when every one is named and are blank
+// otherwise.
+func WriteStubs(w io.Writer, result *ExtractResult) error {
+	var pkgs []string
+	byPkg := make(map[string][]FunctionInfo)
+	seen := make(map[string]bool)
+	for _, fn := range result.Functions {
+		if !fn.Exported || (fn.Receiver != "" && !isExported(fn.Receiver)) {
+			continue
+		}
+		key := fn.Package + "." + fn.Receiver + "." + fn.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, ok := byPkg[fn.Package]; !ok {
+			pkgs = append(pkgs, fn.Package)
+		}
+		byPkg[fn.Package] = append(byPkg[fn.Package], fn)
+	}
+	imports := make(map[string]map[string]string)
+	for _, imp := range result.Imports {
+		name := imp.Name
+		if name == "" {
+			name = importName(imp.Path)
+		}
+		if imports[imp.File] == nil {
+			imports[imp.File] = make(map[string]string)
+		}
+		imports[imp.File][name] = imp.Path
+	}
+	structs := make(map[string]StructInfo)
+	for _, s := range result.Structs {
+		if _, ok := structs[s.Package+"."+s.Name]; !ok {
+			structs[s.Package+"."+s.Name] = s
+		}
+	}
+
+	var b strings.Builder
+	for i, pkg := range pkgs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		refs := newStubRefs()
+		for _, fn := range byPkg[pkg] {
+			refs.addFunc(fn, imports[fn.File])
+		}
+		// Declaring a type may bring in more types through the constraints
+		// of its type parameters.
+		var decls []string
+		for j := 0; j < len(refs.locals); j++ {
+			name := refs.locals[j]
+			s, scanned := structs[pkg+"."+name]
+			var params []string
+			switch {
+			case scanned && len(s.TypeParams) > 0:
+				params = s.TypeParams
+				scope := make(map[string]bool)
+				for _, p := range params {
+					n, _, _ := strings.Cut(p, " ")
+					scope[n] = true
+				}
+				for _, p := range params {
+					_, constraint, _ := strings.Cut(p, " ")
+					refs.add(constraint, scope, imports[s.File])
+				}
+			default:
+				for k := 0; k < refs.arity[name]; k++ {
+					params = append(params, fmt.Sprintf("T%d any", k))
+				}
+			}
+			decl := "type " + name
+			if len(params) > 0 {
+				decl += "[" + strings.Join(params, ", ") + "]"
+			}
+			decls = append(decls, decl+" struct{}\n")
+		}
+
+		fmt.Fprintf(&b, "package %s\n", pkg)
+		if len(refs.imports) > 0 {
+			names := make([]string, 0, len(refs.imports))
+			for name := range refs.imports {
+				names = append(names, name)
+			}
+			sort.Slice(names, func(x, y int) bool { return refs.imports[names[x]] < refs.imports[names[y]] })
+			b.WriteString("\nimport (\n")
+			for _, name := range names {
+				path := refs.imports[name]
+				if name == importName(path) {
+					fmt.Fprintf(&b, "\t%q\n", path)
+				} else {
+					fmt.Fprintf(&b, "\t%s %q\n", name, path)
+				}
+			}
+			b.WriteString(")\n")
+		}
+		if len(decls) > 0 {
+			b.WriteString("\n" + strings.Join(decls, ""))
+		}
+		for _, fn := range byPkg[pkg] {
+			b.WriteString("\n" + stubSignature(fn) + " {\n\tpanic(\"not implemented\")\n}\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// stubRefs collects the names the stubs of one package refer to.
+type stubRefs struct {
+	// imports maps each package qualifier used to its import path.
+	imports map[string]string
+	// locals lists the package's own types in first-mentioned order, and
+	// arity gives the number of type arguments each was mentioned with.
+	locals []string
+	arity  map[string]int
+}
+
+func newStubRefs() *stubRefs {
+	return &stubRefs{imports: make(map[string]string), arity: make(map[string]int)}
+}
+
+// addFunc records the types in the signature of fn, whose file has the
+// given imports, keyed by local name.
+func (r *stubRefs) addFunc(fn FunctionInfo, imports map[string]string) {
+	scope := make(map[string]bool)
+	var exprs []string
+	if fn.Receiver != "" {
+		// The receiver's type arguments, as in List[T], declare parameters.
+		if expr, err := parser.ParseExpr(fn.ReceiverFull); err == nil {
+			var args []ast.Expr
+			switch e := expr.(type) {
+			case *ast.IndexExpr:
+				args = []ast.Expr{e.Index}
+			case *ast.IndexListExpr:
+				args = e.Indices
+			}
+			for _, arg := range args {
+				if id, ok := arg.(*ast.Ident); ok {
+					scope[id.Name] = true
+				}
+			}
+			r.addLocal(fn.Receiver, len(args))
+		}
+	}
+	for _, p := range fn.TypeParams {
+		name, constraint, _ := strings.Cut(p, " ")
+		scope[name] = true
+		exprs = append(exprs, constraint)
+	}
+	exprs = append(exprs, fn.ParamTypes...)
+	exprs = append(exprs, fn.Results...)
+	for _, t := range exprs {
+		r.add(t, scope, imports)
+	}
+}
+
+// add records the types mentioned by the type expression typ. Names in
+// scope are type parameters rather than types of the package.
+func (r *stubRefs) add(typ string, scope map[string]bool, imports map[string]string) {
+	expr, err := parser.ParseExpr(strings.TrimPrefix(typ, "..."))
+	if err != nil {
+		return
+	}
+	var visit func(n ast.Node) bool
+	visit = func(n ast.Node) bool {
+		switch e := n.(type) {
+		case *ast.Field:
+			// Skip parameter, field, and method names.
+			ast.Inspect(e.Type, visit)
+			return false
+		case *ast.ArrayType:
+			// The length is a constant, not a type.
+			ast.Inspect(e.Elt, visit)
+			return false
+		case *ast.SelectorExpr:
+			if x, ok := e.X.(*ast.Ident); ok {
+				if path, ok := imports[x.Name]; ok {
+					r.imports[x.Name] = path
+				}
+			}
+			return false
+		case *ast.IndexExpr:
+			if x, ok := e.X.(*ast.Ident); ok && !scope[x.Name] {
+				r.addLocal(x.Name, 1)
+			}
+		case *ast.IndexListExpr:
+			if x, ok := e.X.(*ast.Ident); ok && !scope[x.Name] {
+				r.addLocal(x.Name, len(e.Indices))
+			}
+		case *ast.Ident:
+			if !scope[e.Name] {
+				r.addLocal(e.Name, 0)
+			}
+		}
+		return true
+	}
+	ast.Inspect(expr, visit)
+}
+
+// addLocal records a type of the package, unless name is predeclared.
+func (r *stubRefs) addLocal(name string, arity int) {
+	if _, predeclared := types.Universe.Lookup(name).(*types.TypeName); predeclared {
+		return
+	}
+	if _, ok := r.arity[name]; !ok {
+		r.locals = append(r.locals, name)
+	}
+	r.arity[name] = max(r.arity[name], arity)
+}
+
+// stubSignature renders the func line of fn, without its body.
+func stubSignature(fn FunctionInfo) string {
+	sig := "func "
+	if fn.Receiver != "" {
+		recv := fn.ReceiverFull
+		if fn.PointerReceiver {
+			recv = "*" + recv
+		}
+		sig += "(" + recv + ") "
+	}
+	params := make([]string, len(fn.ParamTypes))
+	named := len(fn.Params) == len(fn.ParamTypes)
+	for i, t := range fn.ParamTypes {
+		name := "_"
+		if named {
+			name = fn.Params[i]
+		}
+		params[i] = name + " " + t
+	}
+	sig += fn.Name
+	if len(fn.TypeParams) > 0 {
+		sig += "[" + strings.Join(fn.TypeParams, ", ") + "]"
+	}
+	sig += "(" + strings.Join(params, ", ") + ")"
+	switch len(fn.Results) {
+	case 0:
+	case 1:
+		sig += " " + fn.Results[0]
+	default:
+		sig += " (" + strings.Join(fn.Results, ", ") + ")"
+	}
+	return sig
+}
@@ -0,0 +1,49 @@
+package extract
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// stripTemplateActions makes a best-effort, lossy rewrite of a Go template
+// into parseable Go. An action alone on its line ({{if .X}}, {{end}},
+// {{template "x"}}) becomes a comment, so control structure around
+// declarations and statements disappears; any other action becomes an opaque
+// identifier _tmpl0, _tmpl1, ... in place. Newlines inside actions are kept so
+// line numbers still match the template, though columns and offsets after an
+// action may not. An action missing its closing braces is left as is.
+func stripTemplateActions(src []byte) []byte {
+	var out bytes.Buffer
+	n := 0
+	for {
+		open := bytes.Index(src, []byte("{{"))
+		if open < 0 {
+			break
+		}
+		closing := bytes.Index(src[open+2:], []byte("}}"))
+		if closing < 0 {
+			break
+		}
+		end := open + 2 + closing + 2
+		newlines := bytes.Repeat([]byte("\n"), bytes.Count(src[open:end], []byte("\n")))
+
+		lineStart := bytes.LastIndexByte(src[:open], '\n') + 1
+		lineEnd := bytes.IndexByte(src[end:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(src) - end
+		}
+		out.Write(src[:open])
+		if len(bytes.TrimSpace(src[lineStart:open])) == 0 && len(bytes.TrimSpace(src[end:end+lineEnd])) == 0 {
+			out.WriteString("/*tmpl")
+			out.Write(newlines)
+			out.WriteString("*/")
+		} else {
+			out.WriteString("_tmpl" + strconv.Itoa(n))
+			out.Write(newlines)
+			n++
+		}
+		src = src[end:]
+	}
+	out.Write(src)
+	return out.Bytes()
+}
@@ -0,0 +1,64 @@
+package extract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStripTemplateActions(t *testing.T) {
+	src := "package {{.Package}}\n\n{{range .Types}}\ntype {{.Name}} struct {\n\tID int\n}\n{{end}}\n\nfunc New() *T {\n\treturn &T{ID: {{.Default\n}}}\n}\n"
+	expected := "package _tmpl0\n\n/*tmpl*/\ntype _tmpl1 struct {\n\tID int\n}\n/*tmpl*/\n\nfunc New() *T {\n\treturn &T{ID: _tmpl2\n}\n}\n"
+	if got := string(stripTemplateActions([]byte(src))); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	if got := string(stripTemplateActions([]byte("x := {{.Open"))); got != "x := {{.Open" {
+		t.Errorf("expected unclosed action to be kept, got %q", got)
+	}
+}
+
+func TestExtractTemplate(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "model.go.tmpl")
+	os.WriteFile(src, []byte(`package {{.Package}}
+
+// {{.Name}} is generated.
+type {{.Name}} struct {
+	{{range .Fields}}
+	{{.Name}} {{.Type}}
+	{{end}}
+}
+
+func (m *{{.Name}}) Validate() error {
+	{{if .Strict}}
+	return check(m)
+	{{end}}
+	return nil
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{Template: true})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Structs) != 1 || result.Structs[0].Line != 4 {
+		t.Errorf("expected one struct on line 4, got %+v", result.Structs)
+	}
+	if len(result.Functions) != 1 || result.Functions[0].Name != "Validate" || result.Functions[0].Line != 10 {
+		t.Errorf("expected Validate on line 10, got %+v", result.Functions)
+	}
+}
+
+func TestExtractTemplateSkipGenerated(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "model.go.tmpl")
+	os.WriteFile(src, []byte("// Code generated by gen. DO NOT EDIT.\n\npackage {{.Package}}\n\nfunc {{.Name}}() {}\n"), 0644)
+
+	result, err := extractFile(src, Options{Template: true, SkipGenerated: true})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if !result.IsEmpty() || len(result.Files) != 0 {
+		t.Errorf("expected the generated template to be skipped, got %+v", result)
+	}
+}
@@ -0,0 +1,246 @@
+// Package extract extracts functions, structs, interfaces, and package-level
+// values from Go source files without type checking, and writes them in the
+// formats the go-extract command offers. ExtractDir and StreamDir walk a
+// directory tree the way the go tool does; ExtractFiles takes explicit paths.
+package extract
+
+// ExtractResult holds the combined extraction results from one or more Go source files.
+type ExtractResult struct {
+	Functions       []FunctionInfo  `json:"functions"`
+	Structs         []StructInfo    `json:"structs"`
+	Interfaces      []InterfaceInfo `json:"interfaces"`
+	Values          []ValueInfo     `json:"values"`
+	Imports         []ImportInfo    `json:"imports"`
+	Examples        []ExampleInfo   `json:"examples"`
+	Assertions      []AssertionInfo `json:"assertions"`
+	Dependencies    []string        `json:"dependencies"`
+	StdDependencies []string        `json:"std_dependencies"`
+	ModulePath      string          `json:"module_path,omitempty"`
+	Files           []FileInfo      `json:"files,omitempty"`
+
+	// aliases lists the package-level type aliases, for attachMethods.
+	aliases []typeAlias
+}
+
+// newResult returns an empty ExtractResult whose slices encode as [] rather than null.
+func newResult() *ExtractResult {
+	return &ExtractResult{
+		Functions:       []FunctionInfo{},
+		Structs:         []StructInfo{},
+		Interfaces:      []InterfaceInfo{},
+		Values:          []ValueInfo{},
+		Imports:         []ImportInfo{},
+		Examples:        []ExampleInfo{},
+		Assertions:      []AssertionInfo{},
+		Dependencies:    []string{},
+		StdDependencies: []string{},
+	}
+}
+
+// merge appends the entities of other to r.
+func (r *ExtractResult) merge(other *ExtractResult) {
+	r.Functions = append(r.Functions, other.Functions...)
+	r.Structs = append(r.Structs, other.Structs...)
+	r.Interfaces = append(r.Interfaces, other.Interfaces...)
+	// Groups are numbered from 1 per file; shift other's past r's so they
+	// stay distinct in the combined result.
+	offset := 0
+	if n := len(r.Values); n > 0 {
+		offset = r.Values[n-1].Group
+	}
+	for _, v := range other.Values {
+		v.Group += offset
+		r.Values = append(r.Values, v)
+	}
+	r.Imports = append(r.Imports, other.Imports...)
+	r.Examples = append(r.Examples, other.Examples...)
+	r.Assertions = append(r.Assertions, other.Assertions...)
+	r.Files = append(r.Files, other.Files...)
+	r.aliases = append(r.aliases, other.aliases...)
+}
+
+// IsEmpty reports whether r holds no entities. Imports, examples (which are
+// also listed as functions) and assertions (also listed as values) don't
+// count.
+func (r *ExtractResult) IsEmpty() bool {
+	return len(r.Functions) == 0 && len(r.Structs) == 0 && len(r.Interfaces) == 0 && len(r.Values) == 0
+}
+
+// FunctionInfo describes a function or method extracted from Go source.
+type FunctionInfo struct {
+	ID               string          `json:"id"`
+	Name             string          `json:"name"`
+	File             string          `json:"file"`
+	Package          string          `json:"package"`
+	GOOS             string          `json:"goos,omitempty"`
+	GOARCH           string          `json:"goarch,omitempty"`
+	Line             int             `json:"line"`
+	Column           int             `json:"column"`
+	Offset           int             `json:"offset"`
+	EndLine          int             `json:"end_line"`
+	LOC              int             `json:"loc"`
+	BodyLOC          int             `json:"body_loc"`
+	Body             string          `json:"body"`
+	Doc              string          `json:"doc"`
+	DocLines         int             `json:"doc_lines"`
+	TypeParams       []string        `json:"type_params"`
+	Params           []string        `json:"params"`
+	ParamTypes       []string        `json:"param_types"`
+	Results          []string        `json:"results"`
+	LargeValueParams []string        `json:"large_value_params"`
+	IsVariadic       bool            `json:"is_variadic"`
+	IsInit           bool            `json:"is_init"`
+	IsMain           bool            `json:"is_main"`
+	Complexity       int             `json:"complexity"`
+	SwitchCases      int             `json:"switch_cases"`
+	SelectCases      int             `json:"select_cases"`
+	AssignCount      int             `json:"assign_count"`
+	MagicNumbers     int             `json:"magic_numbers"`
+	CommentDensity   float64         `json:"comment_density"`
+	Halstead         HalsteadMetrics `json:"halstead"`
+	NormalizedHash   string          `json:"normalized_hash"`
+	HasGoto          bool            `json:"has_goto"`
+	LabeledStmts     int             `json:"labeled_stmts"`
+	NeverReturns     bool            `json:"never_returns"`
+	ConstantReturn   string          `json:"constant_return,omitempty"`
+	ReferencedTypes  []string        `json:"referenced_types"`
+	ShadowsBuiltin   []string        `json:"shadows_builtin"`
+	UsesReflection   bool            `json:"uses_reflection"`
+	IsFuncLit        bool            `json:"is_func_lit"`
+	Captures         []string        `json:"captures,omitempty"`
+	Receiver         string          `json:"receiver,omitempty"`
+	ReceiverFull     string          `json:"receiver_full,omitempty"`
+	ReceiverName     string          `json:"receiver_name,omitempty"`
+	PointerReceiver  bool            `json:"pointer_receiver"`
+	IsAccessor       bool            `json:"is_accessor"`
+	IsEmpty          bool            `json:"is_empty"`
+	HasNoBody        bool            `json:"has_no_body"`
+	Exported         bool            `json:"exported"`
+	ExternalCalls    int             `json:"external_calls"`
+	InternalCalls    int             `json:"internal_calls"`
+	Calls            []string        `json:"calls"`
+	CalledMethods    []string        `json:"called_methods"`
+	LocksAcquired    []string        `json:"locks_acquired"`
+	LocksReleased    []string        `json:"locks_released"`
+	IgnoredErrors    int             `json:"ignored_errors"`
+	TakesContext     bool            `json:"takes_context"`
+	ContextIsFirst   bool            `json:"context_is_first"`
+	CustomMetric     *float64        `json:"custom_metric,omitempty"`
+
+	// selectors counts the x.Name selectors in the body, other than package
+	// qualifiers and method calls, for countFieldUsage.
+	selectors map[string]int
+	// identifiers holds every identifier in the body, for -uses.
+	identifiers map[string]bool
+	// discards lists the calls whose results are dropped, for
+	// countIgnoredErrors.
+	discards []discardedCall
+}
+
+// StructInfo describes a struct type extracted from Go source.
+type StructInfo struct {
+	ID                  string      `json:"id"`
+	Name                string      `json:"name"`
+	File                string      `json:"file"`
+	Package             string      `json:"package"`
+	GOOS                string      `json:"goos,omitempty"`
+	GOARCH              string      `json:"goarch,omitempty"`
+	Line                int         `json:"line"`
+	Column              int         `json:"column"`
+	Offset              int         `json:"offset"`
+	LOC                 int         `json:"loc"`
+	Doc                 string      `json:"doc"`
+	DocLines            int         `json:"doc_lines"`
+	TypeParams          []string    `json:"type_params"`
+	Methods             []string    `json:"methods"`
+	Fields              []string    `json:"fields"`
+	FieldZeroValues     []string    `json:"field_zero_values"`
+	FieldUsage          []int       `json:"field_usage"`
+	JSONFields          []JSONField `json:"json_fields,omitempty"`
+	Embedded            []string    `json:"embedded"`
+	Exported            bool        `json:"exported"`
+	AllPointerMethods   bool        `json:"all_pointer_methods"`
+	HasStringMethod     bool        `json:"has_string_method"`
+	NumFields           int         `json:"num_fields"`
+	NumMethods          int         `json:"num_methods"`
+	NumEmbedded         int         `json:"num_embedded"`
+	SizeBytes           int         `json:"size_bytes"`
+	PaddingBytes        int         `json:"padding_bytes"`
+	SuggestedFieldOrder []string    `json:"suggested_field_order,omitempty"`
+	PromotedFields      []string    `json:"promoted_fields,omitempty"`
+}
+
+// InterfaceInfo describes an interface type extracted from Go source.
+type InterfaceInfo struct {
+	ID            string       `json:"id"`
+	Name          string       `json:"name"`
+	File          string       `json:"file"`
+	Package       string       `json:"package"`
+	GOOS          string       `json:"goos,omitempty"`
+	GOARCH        string       `json:"goarch,omitempty"`
+	Line          int          `json:"line"`
+	Column        int          `json:"column"`
+	Offset        int          `json:"offset"`
+	Doc           string       `json:"doc"`
+	DocLines      int          `json:"doc_lines"`
+	TypeParams    []string     `json:"type_params"`
+	Methods       []string     `json:"methods"`
+	Signatures    []string     `json:"signatures"`
+	MethodDetails []MethodInfo `json:"method_details"`
+	Embedded      []string     `json:"embedded"`
+	Implementers  []string     `json:"implementers"`
+}
+
+// MethodInfo describes the full signature of an interface method.
+type MethodInfo struct {
+	Name    string   `json:"name"`
+	Params  []Param  `json:"params"`
+	Returns []string `json:"returns"`
+}
+
+// Param is one named, typed parameter of a method.
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ValueInfo describes a package-level constant or variable extracted from Go
+// source.
+type ValueInfo struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	File     string `json:"file"`
+	Package  string `json:"package"`
+	GOOS     string `json:"goos,omitempty"`
+	GOARCH   string `json:"goarch,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Offset   int    `json:"offset"`
+	Kind     string `json:"kind"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	IntValue *int64 `json:"int_value,omitempty"`
+	Group    int    `json:"group"`
+	Exported bool   `json:"exported"`
+	Doc      string `json:"doc"`
+	DocLines int    `json:"doc_lines"`
+}
+
+// ImportInfo describes one import declaration of a Go source file.
+type ImportInfo struct {
+	File     string `json:"file"`
+	Package  string `json:"package"`
+	Line     int    `json:"line"`
+	Name     string `json:"name,omitempty"`
+	Path     string `json:"path"`
+	Std      bool   `json:"std"`
+	Internal bool   `json:"internal"`
+}
+
+// FileInfo maps a source file to its package, as emitted by -packages-only,
+// and with -file-hashes carries the hex SHA-256 of the file's bytes.
+type FileInfo struct {
+	File     string `json:"file"`
+	Package  string `json:"package"`
+	FileHash string `json:"file_hash,omitempty"`
+}
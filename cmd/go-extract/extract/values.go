@@ -0,0 +1,210 @@
+package extract
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+)
+
+// extractValues extracts a ValueInfo for each name declared by a top-level
+// const or var declaration. Value is the raw source text of the name's
+// initializer; const specs that omit their values repeat the previous spec's
+// expressions (and type), as in Go. consts accumulates the file's evaluated
+// constants so later declarations can refer to earlier ones. Every value of
+// the declaration, parenthesized block or single spec, gets the same group.
+func extractValues(fset *token.FileSet, decl *ast.GenDecl, filename, src string, group int, consts map[string]constant.Value) []ValueInfo {
+	kind := "var"
+	if decl.Tok == token.CONST {
+		kind = "const"
+	}
+	var values []ValueInfo
+	var prevType ast.Expr
+	var prevValues []ast.Expr
+	for specIndex, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		typ, exprs := vs.Type, vs.Values
+		if kind == "const" {
+			if len(exprs) == 0 && typ == nil {
+				typ, exprs = prevType, prevValues
+			}
+			prevType, prevValues = typ, exprs
+		}
+		doc, docLines := docComment(fset, specDoc(decl, vs.Doc))
+		for i, name := range vs.Names {
+			var expr ast.Expr
+			switch {
+			case i < len(exprs):
+				expr = exprs[i]
+			case len(exprs) == 1:
+				// var a, b = f(): every name shares the one call.
+				expr = exprs[0]
+			}
+			pos := fset.Position(name.Pos())
+			vi := ValueInfo{
+				Name:     name.Name,
+				File:     filename,
+				Line:     pos.Line,
+				Column:   pos.Column,
+				Offset:   pos.Offset,
+				Kind:     kind,
+				Group:    group,
+				Exported: isExported(name.Name),
+				Doc:      doc,
+				DocLines: docLines,
+			}
+			if typ != nil {
+				vi.Type = typeString(typ)
+			}
+			if expr != nil {
+				vi.Value = nodeText(fset, expr, src)
+			}
+			if kind == "const" && expr != nil {
+				v := evalConst(expr, int64(specIndex), consts)
+				if id, ok := typ.(*ast.Ident); ok {
+					// A typed constant is converted to its type.
+					v = convertConst(id.Name, v)
+				}
+				if v.Kind() != constant.Unknown {
+					if name.Name != "_" {
+						consts[name.Name] = v
+					}
+					if v.Kind() == constant.Int {
+						if n, exact := constant.Int64Val(v); exact {
+							vi.IntValue = &n
+						}
+					}
+				}
+			}
+			if name.Name != "_" {
+				values = append(values, vi)
+			}
+		}
+	}
+	return values
+}
+
+// nodeText returns the source text of node, or "" if its offsets fall outside src.
+func nodeText(fset *token.FileSet, node ast.Node, src string) string {
+	start := fset.Position(node.Pos()).Offset
+	end := fset.Position(node.End()).Offset
+	if start < 0 || end > len(src) || start > end {
+		return ""
+	}
+	return src[start:end]
+}
+
+// evalConst makes a best-effort evaluation of a constant expression without
+// type checking. It understands literals, iota, true/false, earlier constants
+// in consts, unary and binary operators, shifts, and single-argument
+// conversions. Conversions to predeclared types change the value's kind, so
+// float64(1)/3 divides as floats, and yield Unknown when the value doesn't
+// convert exactly, as with int(1.5); conversions to named types such as
+// Weekday(iota) keep the value as it is, since the underlying type isn't
+// known. Anything else, including operands of mismatched kinds, yields an
+// Unknown value.
+func evalConst(expr ast.Expr, iotaValue int64, consts map[string]constant.Value) (v constant.Value) {
+	defer func() {
+		// go/constant panics on operand kinds it cannot combine.
+		if recover() != nil {
+			v = constant.MakeUnknown()
+		}
+	}()
+	return evalConstExpr(expr, iotaValue, consts)
+}
+
+// evalConstExpr does the recursive work of evalConst.
+func evalConstExpr(expr ast.Expr, iotaValue int64, consts map[string]constant.Value) constant.Value {
+	unknown := constant.MakeUnknown()
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(e.Value, e.Kind, 0)
+	case *ast.Ident:
+		switch e.Name {
+		case "iota":
+			return constant.MakeInt64(iotaValue)
+		case "true", "false":
+			return constant.MakeBool(e.Name == "true")
+		}
+		if v, ok := consts[e.Name]; ok {
+			return v
+		}
+		return unknown
+	case *ast.ParenExpr:
+		return evalConstExpr(e.X, iotaValue, consts)
+	case *ast.UnaryExpr:
+		x := evalConstExpr(e.X, iotaValue, consts)
+		if x.Kind() == constant.Unknown {
+			return unknown
+		}
+		return constant.UnaryOp(e.Op, x, 0)
+	case *ast.BinaryExpr:
+		x := evalConstExpr(e.X, iotaValue, consts)
+		y := evalConstExpr(e.Y, iotaValue, consts)
+		if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+			return unknown
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(y)
+			if !ok {
+				return unknown
+			}
+			return constant.Shift(x, e.Op, uint(s))
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, e.Op, y))
+		case token.QUO, token.REM:
+			if constant.Sign(y) == 0 {
+				return unknown
+			}
+			if e.Op == token.QUO && x.Kind() == constant.Int && y.Kind() == constant.Int {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y)
+			}
+		}
+		return constant.BinaryOp(x, e.Op, y)
+	case *ast.CallExpr:
+		if len(e.Args) != 1 || e.Ellipsis.IsValid() {
+			return unknown
+		}
+		x := evalConstExpr(e.Args[0], iotaValue, consts)
+		switch fun := ast.Unparen(e.Fun).(type) {
+		case *ast.Ident:
+			switch fun.Name {
+			case "len", "cap", "real", "imag", "complex", "min", "max":
+				return unknown
+			}
+			return convertConst(fun.Name, x)
+		case *ast.SelectorExpr:
+			return x
+		}
+		return unknown
+	}
+	return unknown
+}
+
+// convertConst converts x to the type called name as a conversion T(x)
+// would: predeclared numeric types take the matching kind, or Unknown if x
+// is not exactly representable in it, and string and bool require a value
+// of that kind already. Other names are taken to be named types, whose
+// underlying type isn't known, and leave x as it is.
+func convertConst(name string, x constant.Value) constant.Value {
+	switch name {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte", "rune":
+		return constant.ToInt(x)
+	case "float32", "float64":
+		return constant.ToFloat(x)
+	case "complex64", "complex128":
+		return constant.ToComplex(x)
+	case "string":
+		if x.Kind() != constant.String {
+			return constant.MakeUnknown()
+		}
+	case "bool":
+		if x.Kind() != constant.Bool {
+			return constant.MakeUnknown()
+		}
+	}
+	return x
+}
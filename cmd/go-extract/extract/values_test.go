@@ -0,0 +1,154 @@
+package extract
+
+import (
+	"go/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractValues(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "values.go")
+	os.WriteFile(src, []byte(`package main
+
+type Perm uint8
+
+const (
+	Read Perm = 1 << iota
+	Write
+	Exec
+	_
+	All = Read | Write | Exec
+)
+
+const Name, Size = "x", 4 * KB
+
+var handler = func() {}
+
+var a, b = split()
+
+func f() {
+	const local = 1
+}
+`), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if len(result.Values) != 9 {
+		t.Fatalf("expected 9 values, got %+v", result.Values)
+	}
+
+	type want struct {
+		kind, typ, value string
+		intValue         int64
+		resolved         bool
+	}
+	expected := map[string]want{
+		"Read":    {"const", "Perm", "1 << iota", 1, true},
+		"Write":   {"const", "Perm", "1 << iota", 2, true},
+		"Exec":    {"const", "Perm", "1 << iota", 4, true},
+		"All":     {"const", "", "Read | Write | Exec", 7, true},
+		"Name":    {"const", "", `"x"`, 0, false},
+		"Size":    {"const", "", "4 * KB", 0, false},
+		"handler": {"var", "", "func() {}", 0, false},
+		"b":       {"var", "", "split()", 0, false},
+	}
+	for _, v := range result.Values {
+		w, ok := expected[v.Name]
+		if !ok {
+			continue
+		}
+		if v.Kind != w.kind || v.Type != w.typ || v.Value != w.value {
+			t.Errorf("%s: expected %s %q = %q, got %s %q = %q", v.Name, w.kind, w.typ, w.value, v.Kind, v.Type, v.Value)
+		}
+		if w.resolved != (v.IntValue != nil) || (v.IntValue != nil && *v.IntValue != w.intValue) {
+			t.Errorf("%s: expected int value %d (resolved %v), got %v", v.Name, w.intValue, w.resolved, v.IntValue)
+		}
+		if v.ID == "" || v.Package != "main" {
+			t.Errorf("%s: expected ID and package to be set, got %+v", v.Name, v)
+		}
+	}
+	if len(result.Functions) != 2 {
+		t.Errorf("expected handler to also be extracted as a function, got %d functions", len(result.Functions))
+	}
+}
+
+func TestExtractValueGroups(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	os.WriteFile(a, []byte(`package main
+
+const (
+	Red = iota
+	Green
+)
+
+var Debug = false
+
+func f() {
+	const local = 1
+}
+
+var (
+	x, y = 1, 2
+)
+`), 0644)
+	os.WriteFile(b, []byte("package main\n\nconst Max = 10\n\nconst (\n\tA = 1\n\tB = 2\n)\n"), 0644)
+
+	result := ExtractFiles([]string{a, b}, Options{})
+
+	expected := map[string]int{"Red": 1, "Green": 1, "Debug": 2, "x": 3, "y": 3, "Max": 4, "A": 5, "B": 5}
+	if len(result.Values) != len(expected) {
+		t.Fatalf("expected %d values, got %+v", len(expected), result.Values)
+	}
+	for _, v := range result.Values {
+		if v.Group != expected[v.Name] {
+			t.Errorf("%s: expected group %d, got %d", v.Name, expected[v.Name], v.Group)
+		}
+	}
+}
+
+func TestEvalConstConversions(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"float64(1)/3", "0.333333"},
+		{"int(7.0)/2", "3"},
+		{"int(1.5)", "unknown"},
+		{"uint8(3) << 2", "12"},
+		{"string(65)", "unknown"},
+		{"Weekday(2)", "2"},
+		{"time.Duration(5) * 2", "10"},
+	}
+	for _, tt := range tests {
+		expr, err := parser.ParseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("parsing %s failed: %v", tt.expr, err)
+		}
+		if got := evalConst(expr, 0, nil).String(); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestExtractTypedConstDivision(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "ratio.go")
+	os.WriteFile(src, []byte("package main\n\nconst One float64 = 1\n\nconst Third = One / 3\n\nconst Half = 1 / 2\n"), 0644)
+
+	result, err := extractFile(src, Options{})
+	if err != nil {
+		t.Fatalf("extractFile failed: %v", err)
+	}
+	if third := result.Values[1]; third.IntValue != nil {
+		t.Errorf("expected One / 3 to be a float without an int value, got %d", *third.IntValue)
+	}
+	if half := result.Values[2]; half.IntValue == nil || *half.IntValue != 0 {
+		t.Errorf("expected 1 / 2 to be the int 0, got %v", half.IntValue)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		if _, err := runGit(dir, args...); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "test")
+	os.MkdirAll(filepath.Join(dir, "pkg"), 0755)
+	os.WriteFile(filepath.Join(dir, "pkg", "kept.go"), []byte("package pkg\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "pkg", "changed.go"), []byte("package pkg\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "deleted.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("x\n"), 0644)
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+
+	os.WriteFile(filepath.Join(dir, "pkg", "changed.go"), []byte("package pkg\n\nfunc F() {}\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("y\n"), 0644)
+	os.Remove(filepath.Join(dir, "deleted.go"))
+
+	files, err := gitChangedFiles(filepath.Join(dir, "pkg"), "HEAD")
+	if err != nil {
+		t.Fatalf("gitChangedFiles failed: %v", err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) != "changed.go" {
+		t.Fatalf("expected only changed.go, got %v", files)
+	}
+	if _, err := os.Stat(files[0]); err != nil {
+		t.Errorf("expected an existing path, got %s", files[0])
+	}
+
+	if _, err := gitChangedFiles(dir, "no-such-revision"); err == nil {
+		t.Errorf("expected error for an unknown revision")
+	}
+}
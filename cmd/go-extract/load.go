@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// resolvePackageArgs expands -pkg mode arguments into Go source file paths.
+// Arguments naming a regular file are passed through unchanged; everything
+// else is treated as a package pattern and loaded via go/packages, so build
+// tags and file selection follow the Go toolchain.
+func resolvePackageArgs(args []string) ([]string, error) {
+	var files []string
+	var patterns []string
+	for _, arg := range args {
+		if info, err := os.Stat(arg); err == nil && info.Mode().IsRegular() {
+			files = append(files, arg)
+			continue
+		}
+		patterns = append(patterns, arg)
+	}
+	if len(patterns) == 0 {
+		return files, nil
+	}
+
+	pkgFiles, err := loadPackageFiles(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return append(files, pkgFiles...), nil
+}
+
+// loadPackageFiles loads the given package patterns and returns the Go files
+// belonging to each matched package.
+func loadPackageFiles(patterns []string) ([]string, error) {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("loading packages: %w", err)
+	}
+
+	var files []string
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			fileError(pkg.PkgPath, e)
+		}
+		files = append(files, pkg.GoFiles...)
+	}
+	return files, nil
+}
+
+// readManifest reads a -manifest file: one input path per line, with
+// surrounding whitespace trimmed and blank lines and # comments ignored.
+func readManifest(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	return paths, nil
+}
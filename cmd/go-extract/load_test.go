@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePackageArgsPassesFilesThrough(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "main.go")
+	os.WriteFile(src, []byte("package main\n"), 0644)
+
+	files, err := resolvePackageArgs([]string{src})
+	if err != nil {
+		t.Fatalf("resolvePackageArgs failed: %v", err)
+	}
+	if len(files) != 1 || files[0] != src {
+		t.Errorf("expected [%s], got %v", src, files)
+	}
+}
+
+func TestResolvePackageArgsLoadsPackage(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/demo\n\ngo 1.21\n"), 0644)
+	os.MkdirAll(filepath.Join(dir, "server"), 0755)
+	os.WriteFile(filepath.Join(dir, "server", "server.go"), []byte(`package server
+
+func Start() {}
+`), 0644)
+	os.WriteFile(filepath.Join(dir, "server", "ignored.go"), []byte(`//go:build ignore
+
+package server
+
+func Ignored() {}
+`), 0644)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir failed: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	files, err := resolvePackageArgs([]string{"./server"})
+	if err != nil {
+		t.Fatalf("resolvePackageArgs failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %v", files)
+	}
+	if filepath.Base(files[0]) != "server.go" {
+		t.Errorf("expected server.go, got %s", files[0])
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "list.txt")
+	os.WriteFile(manifest, []byte("# generated\na.go\n\n  pkg/b.go  \n# pkg/c.go\n./internal/server\n"), 0644)
+
+	paths, err := readManifest(manifest)
+	if err != nil {
+		t.Fatalf("readManifest failed: %v", err)
+	}
+	expected := []string{"a.go", "pkg/b.go", "./internal/server"}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+	for i, want := range expected {
+		if paths[i] != want {
+			t.Errorf("path %d: expected %s, got %s", i, want, paths[i])
+		}
+	}
+
+	if _, err := readManifest(filepath.Join(dir, "missing.txt")); err == nil {
+		t.Errorf("expected error for missing manifest")
+	}
+}
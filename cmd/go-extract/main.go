@@ -1,79 +1,412 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"time"
+
+	"github.com/josefsalyer/desloppify/cmd/go-extract/extract"
 )
 
-// ExtractResult holds the combined extraction results from one or more Go source files.
-type ExtractResult struct {
-	Functions  []FunctionInfo  `json:"functions"`
-	Structs    []StructInfo    `json:"structs"`
-	Interfaces []InterfaceInfo `json:"interfaces"`
-}
+// quiet suppresses warnings on stderr; set by the -quiet flag.
+var quiet bool
 
-// FunctionInfo describes a function or method extracted from Go source.
-type FunctionInfo struct {
-	Name     string   `json:"name"`
-	File     string   `json:"file"`
-	Line     int      `json:"line"`
-	EndLine  int      `json:"end_line"`
-	LOC      int      `json:"loc"`
-	Body     string   `json:"body"`
-	Params   []string `json:"params"`
-	Receiver string   `json:"receiver,omitempty"`
-	Exported bool     `json:"exported"`
-}
+// fileErrors counts inputs that could not be read or parsed, for -strict.
+var fileErrors int
 
-// StructInfo describes a struct type extracted from Go source.
-type StructInfo struct {
-	Name     string   `json:"name"`
-	File     string   `json:"file"`
-	Line     int      `json:"line"`
-	LOC      int      `json:"loc"`
-	Methods  []string `json:"methods"`
-	Fields   []string `json:"fields"`
-	Embedded []string `json:"embedded"`
-	Exported bool     `json:"exported"`
+// fileError warns that path could not be extracted and counts the failure.
+func fileError(path string, err error) {
+	fileErrors++
+	warnf("%s: %v", path, err)
 }
 
-// InterfaceInfo describes an interface type extracted from Go source.
-type InterfaceInfo struct {
-	Name    string   `json:"name"`
-	File    string   `json:"file"`
-	Line    int      `json:"line"`
-	Methods []string `json:"methods"`
+// warnf prints a warning to stderr unless -quiet is set.
+func warnf(format string, args ...any) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
 }
 
 func main() {
-	args := os.Args[1:]
-	if len(args) == 0 {
-		fmt.Fprintln(os.Stderr, "Usage: go-extract <file1.go> [file2.go ...]")
+	pkgMode := flag.Bool("pkg", false, "treat arguments as package paths (e.g. ./internal/server) and load them via go/packages")
+	format := flag.String("format", "json", "output format: json, csv (one row per function; structs and interfaces are omitted), lsp (per-file LSP DocumentSymbol outline), stubs (Go stubs of exported functions and methods), duplicates (groups of functions with identical bodies up to identifier names), callgraph (call edges between scanned functions, across packages), proto (length-delimited protobuf stream per extract.proto), markdown (godoc-style documentation of exported types and functions), or prom (per-package aggregate counts in Prometheus text exposition format)")
+	flag.BoolVar(&quiet, "quiet", false, "suppress warnings about files that fail to parse")
+	strict := flag.Bool("strict", false, "exit 1 after writing output if any input file failed to read or parse")
+	diffMode := flag.Bool("diff", false, "compare two extraction JSON files (old.json new.json) and report added, removed, and changed symbols")
+	watchMode := flag.Bool("watch", false, "re-extract whenever a watched file (or a .go file in a watched directory) changes, streaming one JSON line per run")
+	nameFilter := flag.String("name-filter", "", "only emit functions, structs, interfaces, and values whose names match this regular expression")
+	symbol := flag.String("symbol", "", "only emit the functions, methods, structs, interfaces, and values with exactly this name; exit 1 if none match")
+	uses := flag.String("uses", "", "only emit functions whose body mentions this identifier (variable, function, type, or field name)")
+	usesRegex := flag.Bool("uses-regex", false, "treat -uses as a regular expression that must match a whole identifier")
+	minLOC := flag.Int("min-loc", 0, "only emit functions spanning at least this many lines")
+	minComplexity := flag.Int("min-complexity", 0, "only emit functions with at least this cyclomatic complexity; combined with -min-loc, both must hold")
+	lineRange := flag.String("lines", "", "only emit symbols whose declaration starts within START:END (inclusive)")
+	showProgress := flag.Bool("progress", false, "report files processed on stderr (suppressed by -quiet)")
+	flatten := flag.Bool("flatten-embeds", false, "list fields promoted from embedded structs found in the scanned set as promoted_fields")
+	gofmtBody := flag.Bool("gofmt-body", false, "format each function body with gofmt instead of keeping its source text as written")
+	bodyHash := flag.Bool("body-hash", false, "replace each function body with the SHA-256 hex digest of its text")
+	stream := flag.Bool("stream", false, "treat arguments as directories, walked like the go tool does, and write one JSON line per .go file as soon as it is parsed, without the cross-file analyses; only -o, -gzip, and the filtering flags apply")
+	followSymlinks := flag.Bool("follow-symlinks", false, "with -stream, also descend into symlinked directories, walking each directory once")
+	skipTests := flag.Bool("skip-tests", false, "with -stream, leave out _test.go files")
+	exportedOnly := flag.Bool("exported-only", false, "with -stream, keep only exported functions, methods of exported types, structs, interfaces, and values")
+	splitDir := flag.String("split", "", "write one output file per input, named <basename>.<format>, into this directory instead of combining")
+	includeFuncLits := flag.Bool("include-funclits", false, "also extract function literals inside functions, named like Outer.func1, with the outer variables each captures")
+	skipGenerated := flag.Bool("skip-generated", false, "skip files marked \"// Code generated ... DO NOT EDIT.\" before their package clause")
+	largeParamBytes := flag.Int("large-param-bytes", extract.DefaultLargeParamBytes, "list under large_value_params the parameters of scanned struct types estimated larger than this many bytes and passed by value")
+	fileTimeout := flag.Duration("timeout", 0, "skip, with a warning, any file whose extraction takes longer than this (0 means no limit)")
+	templateMode := flag.Bool("tmpl", false, "treat inputs as Go text/template sources: replace {{...}} actions with placeholders before parsing (lossy)")
+	packagesOnly := flag.Bool("packages-only", false, "parse only package clauses and emit a JSON list of {file, package} pairs")
+	relBase := flag.String("rel", "", "emit File fields relative to this base directory")
+	metricExpr := flag.String("metric", "", "compute custom_metric per function from an expression over its numeric fields, e.g. \"LOC*2+Complexity\"")
+	countOnly := flag.Bool("count", false, "print the number of files and extracted symbols to stderr instead of writing the output")
+	outPath := flag.String("o", "", "write output to this file instead of stdout")
+	compress := flag.Bool("gzip", false, "gzip the output; implied when -o ends in .gz")
+	manifest := flag.String("manifest", "", "read input paths from this file, one per line (blank lines and # comments ignored), in addition to any arguments")
+	gitDiff := flag.String("git-diff", "", "also extract the .go files changed since this git revision (deleted files are skipped)")
+	fileHashes := flag.Bool("file-hashes", false, "list each extracted file with the SHA-256 of its bytes under files")
+	bundle := flag.Bool("bundle", false, "treat inputs as bundles of files separated by \"// FILE: path.go\" lines and extract each section as its own file")
+	archive := flag.String("archive", "", "extract the .go entries of a zip, tar, or tar.gz archive instead of files on disk")
+	archiveURL := flag.String("url", "", "download a zip, tar, or tar.gz archive and extract its .go entries in memory")
+	urlTimeout := flag.Duration("url-timeout", 2*time.Minute, "give up on a -url download after this long")
+	resolveModule := flag.Bool("module", false, "read the nearest go.mod to set module_path, mark same-module imports internal, and resolve relative imports")
+	satisfies := flag.String("satisfies", "", "report which structs satisfy this interface, e.g. io.Reader or a scanned interface name, instead of the extraction")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: go-extract [flags] <file1.go|package|dir> [file2.go|package|dir ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := checkStreamFlags(flag.CommandLine, *stream); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	args := flag.Args()
+	if *manifest != "" {
+		paths, err := readManifest(*manifest)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		args = append(args, paths...)
+	}
+	if *gitDiff != "" {
+		files, err := gitChangedFiles(".", *gitDiff)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		args = append(args, files...)
+	}
+	if len(args) == 0 && *archive == "" && *archiveURL == "" && *gitDiff == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	var write func(io.Writer, *extract.ExtractResult) error
+	switch *format {
+	case "json":
+		write = extract.WriteJSON
+	case "csv":
+		write = extract.WriteCSV
+	case "lsp":
+		write = extract.WriteLSP
+	case "stubs":
+		write = extract.WriteStubs
+	case "duplicates":
+		write = extract.WriteDuplicates
+	case "callgraph":
+		write = extract.WriteCallGraph
+	case "proto":
+		write = extract.WriteProto
+	case "markdown":
+		write = extract.WriteMarkdown
+	case "prom":
+		write = extract.WriteProm
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown -format %q\n", *format)
 		os.Exit(1)
 	}
 
-	combined := &ExtractResult{
-		Functions:  []FunctionInfo{},
-		Structs:    []StructInfo{},
-		Interfaces: []InterfaceInfo{},
+	var nameRe *regexp.Regexp
+	if *nameFilter != "" {
+		re, err := regexp.Compile(*nameFilter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid -name-filter: %v\n", err)
+			os.Exit(1)
+		}
+		nameRe = re
+	}
+
+	var usesMatch func(string) bool
+	if *uses != "" {
+		usesMatch = func(name string) bool { return name == *uses }
+		if *usesRegex {
+			re, err := regexp.Compile("^(?:" + *uses + ")$")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: invalid -uses: %v\n", err)
+				os.Exit(1)
+			}
+			usesMatch = re.MatchString
+		}
+	}
+
+	var metric extract.MetricFunc
+	if *metricExpr != "" {
+		var err error
+		metric, err = extract.CompileMetric(*metricExpr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid -metric: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var startLine, endLine int
+	if *lineRange != "" {
+		var err error
+		startLine, endLine, err = extract.ParseLineRange(*lineRange)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: invalid -lines: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *diffMode {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "error: -diff takes exactly two JSON files: old.json new.json")
+			os.Exit(1)
+		}
+		diff, err := extract.DiffFiles(args[0], args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := extract.WriteOutput(*outPath, *compress, func(w io.Writer) error { return extract.WriteIndentedJSON(w, diff) }); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing diff: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *pkgMode {
+		files, err := resolvePackageArgs(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		args = files
+	}
+
+	if *packagesOnly {
+		files := []extract.FileInfo{}
+		for _, path := range args {
+			fi, err := extract.ExtractPackageClause(path)
+			if err != nil {
+				fileError(path, err)
+				continue
+			}
+			files = append(files, fi)
+		}
+		if err := extract.WriteOutput(*outPath, *compress, func(w io.Writer) error { return extract.WriteIndentedJSON(w, files) }); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing packages: %v\n", err)
+			os.Exit(1)
+		}
+		exitIfFileErrors(*strict)
+		return
+	}
+
+	opts := extract.Options{
+		SkipGenerated:   *skipGenerated,
+		Timeout:         *fileTimeout,
+		Template:        *templateMode,
+		IncludeFuncLits: *includeFuncLits,
+		LargeParamBytes: *largeParamBytes,
+		FileError:       fileError,
+	}
+	postprocess := func(result *extract.ExtractResult) *extract.ExtractResult {
+		if !*fileHashes {
+			result.Files = nil
+		}
+		if *resolveModule {
+			extract.ResolveModules(result)
+		}
+		if *flatten {
+			extract.FlattenEmbeds(result)
+		}
+		if metric != nil {
+			extract.ApplyMetric(result, metric)
+		}
+		if nameRe != nil {
+			extract.FilterByName(result, nameRe)
+		}
+		if *lineRange != "" {
+			extract.FilterByLines(result, startLine, endLine)
+		}
+		if usesMatch != nil {
+			extract.FilterByUses(result, usesMatch)
+		}
+		if *minLOC > 0 || *minComplexity > 0 {
+			extract.FilterBySize(result, *minLOC, *minComplexity)
+		}
+		if *symbol != "" {
+			extract.FilterBySymbol(result, *symbol)
+		}
+		if *gofmtBody {
+			extract.GofmtBodies(result)
+		}
+		if *bodyHash {
+			extract.HashBodies(result)
+		}
+		if *relBase != "" {
+			if err := extract.RelativizePaths(result, *relBase); err != nil {
+				warnf("-rel %s: %v", *relBase, err)
+			}
+		}
+		return result
+	}
+
+	if *watchMode {
+		process := func(paths []string) *extract.ExtractResult { return postprocess(extract.ExtractFiles(paths, opts)) }
+		if err := watch(args, process, os.Stdout, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *stream {
+		err := extract.WriteOutput(*outPath, *compress, func(w io.Writer) error {
+			for _, dir := range args {
+				streamOpts := opts
+				streamOpts.FollowSymlinks, streamOpts.Process = *followSymlinks, postprocess
+				streamOpts.SkipTests, streamOpts.ExportedOnly = *skipTests, *exportedOnly
+				if err := extract.StreamDir(dir, streamOpts, w); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		exitIfFileErrors(*strict)
+		return
+	}
+
+	if *splitDir != "" {
+		splitOpts := opts
+		splitOpts.Process = postprocess
+		if err := extract.WriteSplit(args, *splitDir, *format, write, splitOpts); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		exitIfFileErrors(*strict)
+		return
 	}
 
-	for _, arg := range args {
-		result, err := extractFile(arg)
+	var result *extract.ExtractResult
+	files := len(args)
+	if *archive != "" {
+		var err error
+		result, err = extract.ExtractArchive(*archive, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		files = extract.CountFiles(result)
+	} else if *archiveURL != "" {
+		var err error
+		result, err = extract.FetchArchive(*archiveURL, *urlTimeout, opts)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", arg, err)
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		files = extract.CountFiles(result)
+	} else if *bundle {
+		result = extract.ExtractBundles(args, opts)
+		files = extract.CountFiles(result)
+	} else {
+		fileOpts := opts
+		if *showProgress && !quiet {
+			fileOpts.Progress = extract.NewProgress(os.Stderr, len(args), extract.ProgressInterval)
+		}
+		result = extract.ExtractFiles(args, fileOpts)
+		fileOpts.Progress.Finish()
+	}
+
+	result = postprocess(result)
+	if *symbol != "" && result.IsEmpty() {
+		fmt.Fprintf(os.Stderr, "error: no symbol named %q\n", *symbol)
+		os.Exit(1)
+	}
+
+	if *satisfies != "" {
+		infos, err := extract.CheckSatisfies(result, *satisfies)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := extract.WriteOutput(*outPath, *compress, func(w io.Writer) error { return extract.WriteIndentedJSON(w, infos) }); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing satisfies report: %v\n", err)
+			os.Exit(1)
+		}
+		exitIfFileErrors(*strict)
+		return
+	}
+
+	if *countOnly {
+		extract.WriteCounts(os.Stderr, files, result)
+		exitIfFileErrors(*strict)
+		return
+	}
+
+	if err := extract.WriteOutput(*outPath, *compress, func(w io.Writer) error { return write(w, result) }); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", *format, err)
+		os.Exit(1)
+	}
+	exitIfFileErrors(*strict)
+}
+
+// streamOnlyFlags only take effect with -stream. streamConflicts choose
+// inputs, outputs, or exit conditions that -stream, writing one JSON line
+// per file under the directories it walks, cannot honour.
+var (
+	streamOnlyFlags = []string{"follow-symlinks", "skip-tests", "exported-only"}
+	streamConflicts = []string{"format", "count", "progress", "split", "symbol", "satisfies", "archive", "url", "bundle", "pkg", "diff", "watch", "packages-only", "git-diff"}
+)
+
+// checkStreamFlags returns an error naming the first flag set in fs that
+// conflicts with -stream when stream is set, or that needs -stream when it
+// is not. -format json, the format -stream writes, is allowed.
+func checkStreamFlags(fs *flag.FlagSet, stream bool) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !stream {
+		for _, name := range streamOnlyFlags {
+			if set[name] {
+				return fmt.Errorf("-%s requires -stream", name)
+			}
+		}
+		return nil
+	}
+	for _, name := range streamConflicts {
+		if !set[name] || (name == "format" && fs.Lookup(name).Value.String() == "json") {
 			continue
 		}
-		combined.Functions = append(combined.Functions, result.Functions...)
-		combined.Structs = append(combined.Structs, result.Structs...)
-		combined.Interfaces = append(combined.Interfaces, result.Interfaces...)
+		return fmt.Errorf("-stream cannot be combined with -%s", name)
 	}
+	return nil
+}
 
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(combined); err != nil {
-		fmt.Fprintf(os.Stderr, "error encoding JSON: %v\n", err)
+// exitIfFileErrors exits with status 1 when strict is set and any input
+// failed to read or parse.
+func exitIfFileErrors(strict bool) {
+	if strict && fileErrors > 0 {
+		fmt.Fprintf(os.Stderr, "error: %d input(s) failed to read or parse\n", fileErrors)
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the command itself when re-executed by runMain, so tests can
+// check its output and exit status.
+func TestMain(m *testing.M) {
+	if os.Getenv("GO_EXTRACT_RUN_MAIN") == "1" {
+		for i, arg := range os.Args {
+			if arg == "--" {
+				os.Args = append([]string{"go-extract"}, os.Args[i+1:]...)
+				break
+			}
+		}
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs go-extract with args in a child process, returning its stdout,
+// stderr, and exit code.
+func runMain(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=^$", "--"}, args...)...)
+	cmd.Env = append(os.Environ(), "GO_EXTRACT_RUN_MAIN=1")
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("running go-extract: %v", err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+func streamFlagSet(t *testing.T, args ...string) *flag.FlagSet {
+	t.Helper()
+	fs := flag.NewFlagSet("go-extract", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Bool("stream", false, "")
+	fs.Bool("count", false, "")
+	fs.Bool("skip-tests", false, "")
+	fs.String("format", "json", "")
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("parse %v: %v", args, err)
+	}
+	return fs
+}
+
+func TestCheckStreamFlags(t *testing.T) {
+	tests := []struct {
+		args   []string
+		stream bool
+		want   string
+	}{
+		{[]string{"-stream", "-skip-tests"}, true, ""},
+		{[]string{"-stream", "-format", "json"}, true, ""},
+		{[]string{"-stream", "-format", "csv"}, true, "-stream cannot be combined with -format"},
+		{[]string{"-stream", "-count"}, true, "-stream cannot be combined with -count"},
+		{[]string{"-skip-tests"}, false, "-skip-tests requires -stream"},
+		{[]string{"-count", "-format", "csv"}, false, ""},
+	}
+	for _, tt := range tests {
+		err := checkStreamFlags(streamFlagSet(t, tt.args...), tt.stream)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%v: unexpected error %v", tt.args, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("%v: expected error %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
+
+func TestQuietStillCountsFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.go")
+	bad := filepath.Join(dir, "bad.go")
+	os.WriteFile(good, []byte("package p\n\nfunc Run() {}\n"), 0644)
+	os.WriteFile(bad, []byte("package p\n\nfunc {\n"), 0644)
+
+	_, stderr, code := runMain(t, "-count", good, bad)
+	if code != 0 || !strings.Contains(stderr, "warning: ") {
+		t.Errorf("without -quiet: expected a warning and exit 0, got %d, %q", code, stderr)
+	}
+
+	// -count writes its summary to stderr, so that is all -quiet leaves there.
+	_, stderr, code = runMain(t, "-quiet", "-count", good, bad)
+	if code != 0 || strings.Contains(stderr, "warning: ") {
+		t.Errorf("with -quiet: expected no warning and exit 0, got %d, %q", code, stderr)
+	}
+	if !strings.Contains(stderr, "functions: 1") {
+		t.Errorf("with -quiet: expected good.go to be extracted, got %q", stderr)
+	}
+
+	_, stderr, code = runMain(t, "-quiet", "-strict", "-count", good, bad)
+	if code != 1 {
+		t.Errorf("with -quiet -strict: expected exit 1, got %d", code)
+	}
+	if strings.Contains(stderr, "warning: ") || !strings.Contains(stderr, "error: 1 input(s) failed") {
+		t.Errorf("with -quiet -strict: expected only the failure count, got %q", stderr)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/josefsalyer/desloppify/cmd/go-extract/extract"
+)
+
+// watchPollInterval is how often watch checks the watched files for changes.
+const watchPollInterval = 250 * time.Millisecond
+
+// watchDebounce is how long watch waits after the last change it sees before
+// re-extracting, so an editor's burst of writes triggers a single run.
+const watchDebounce = 100 * time.Millisecond
+
+// watchResult is one emission of watch mode: a complete extraction tagged
+// with the time it was produced.
+type watchResult struct {
+	Timestamp time.Time `json:"timestamp"`
+	*extract.ExtractResult
+}
+
+// fileStamp is what watch compares between polls to notice a change.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// watch extracts args once, then again whenever one of the watched Go files
+// changes, writing each result to w as a single JSON line. Arguments may be
+// files or directories; a directory covers the .go files directly inside it
+// (not subdirectories), re-listed on every poll so new and removed files are
+// noticed. Changes are found by polling modification times and sizes every
+// watchPollInterval, which needs nothing beyond the standard library and
+// survives editors that save by renaming a temp file over the original.
+// Watch runs until stop is closed or listing a directory fails; a nil stop
+// runs forever.
+func watch(args []string, process func([]string) *extract.ExtractResult, w io.Writer, stop <-chan struct{}) error {
+	enc := json.NewEncoder(w)
+	emit := func() error {
+		files, err := expandDirs(args)
+		if err != nil {
+			return err
+		}
+		return enc.Encode(watchResult{Timestamp: time.Now().UTC(), ExtractResult: process(files)})
+	}
+	last, err := stampFiles(args)
+	if err != nil {
+		return err
+	}
+	if err := emit(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			stamps, err := stampFiles(args)
+			if err != nil {
+				return err
+			}
+			if !maps.Equal(stamps, last) {
+				last = stamps
+				debounce = time.After(watchDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+			if err := emit(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stampFiles returns the modification time and size of each file args expand
+// to (see expandDirs). Files that cannot be stat'ed, such as one removed
+// mid-save, are left out, so their return shows up as a change.
+func stampFiles(args []string) (map[string]fileStamp, error) {
+	files, err := expandDirs(args)
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]fileStamp, len(files))
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			stamps[file] = fileStamp{info.ModTime(), info.Size()}
+		}
+	}
+	return stamps, nil
+}
+
+// expandDirs replaces each directory in paths with the sorted .go files
+// directly inside it. Other paths are kept as given.
+func expandDirs(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(path, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/josefsalyer/desloppify/cmd/go-extract/extract"
+)
+
+func TestWatchReextractsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "main.go")
+	os.WriteFile(src, []byte("package main\n\nfunc First() {}\n"), 0644)
+
+	pr, pw := io.Pipe()
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		process := func(paths []string) *extract.ExtractResult { return extract.ExtractFiles(paths, extract.Options{}) }
+		done <- watch([]string{dir}, process, pw, stop)
+		pw.Close()
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	lines := make(chan []byte, 16)
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+		for scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(lines)
+	}()
+
+	next := func() extract.ExtractResult {
+		t.Helper()
+		select {
+		case line := <-lines:
+			var got struct {
+				Timestamp time.Time `json:"timestamp"`
+				extract.ExtractResult
+			}
+			if err := json.Unmarshal(line, &got); err != nil {
+				t.Fatalf("decoding watch output failed: %v", err)
+			}
+			if got.Timestamp.IsZero() {
+				t.Errorf("expected a timestamp on each emission")
+			}
+			return got.ExtractResult
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for watch output")
+		}
+		return extract.ExtractResult{}
+	}
+
+	first := next()
+	if len(first.Functions) != 1 || first.Functions[0].Name != "First" {
+		t.Fatalf("expected initial extraction of First, got %v", first.Functions)
+	}
+
+	os.WriteFile(src, []byte("package main\n\nfunc First() {}\n\nfunc Second() {}\n"), 0644)
+
+	second := next()
+	if len(second.Functions) != 2 {
+		t.Errorf("expected 2 functions after change, got %d", len(second.Functions))
+	}
+}
+
+func TestExpandDirs(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "b.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "a.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes\n"), 0644)
+
+	files, err := expandDirs([]string{dir, "other.go"})
+	if err != nil {
+		t.Fatalf("expandDirs failed: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %v", files)
+	}
+	if filepath.Base(files[0]) != "a.go" || filepath.Base(files[1]) != "b.go" || files[2] != "other.go" {
+		t.Errorf("unexpected expansion %v", files)
+	}
+}